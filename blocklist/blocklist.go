@@ -0,0 +1,280 @@
+// Package blocklist ingests Pi-hole/AdGuard-style feeds (hosts files,
+// domain-per-line lists, and Adblock Plus "||domain^" rules) and serves them
+// from an in-memory matcher so checkDomainTrust/checkBlacklist can reject a
+// known-bad domain without a network round trip.
+package blocklist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Matcher holds the deduplicated, normalized set of blocked domains. A
+// domain matches if it equals an entry exactly or is a subdomain of one,
+// mirroring how checkDomainTrust's allowlist already does suffix matching.
+//
+// Entries are tracked per source (a feed URL, or "" for runtime custom
+// entries) so refreshing one feed never clobbers another feed's domains.
+type Matcher struct {
+	mu     sync.RWMutex
+	byFeed map[string]map[string]bool
+}
+
+// NewMatcher returns an empty Matcher, ready to have feeds loaded into it.
+func NewMatcher() *Matcher {
+	return &Matcher{byFeed: make(map[string]map[string]bool)}
+}
+
+// Blocked reports whether domain's eTLD+1 (as computed by Normalize), or
+// any parent of that up to the bare TLD, is present in the matcher.
+func (m *Matcher) Blocked(domain string) bool {
+	normalized, err := Normalize(domain)
+	if err != nil {
+		return false
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	labels := strings.Split(normalized, ".")
+	for i := 0; i < len(labels); i++ {
+		candidate := strings.Join(labels[i:], ".")
+		for _, set := range m.byFeed {
+			if set[candidate] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// customSource is the key runtime Add/Remove entries are stored under, kept
+// separate from feed URLs so a feed refresh never evicts them.
+const customSource = ""
+
+// Add inserts a single normalized domain, for runtime custom entries.
+func (m *Matcher) Add(domain string) error {
+	normalized, err := Normalize(domain)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.byFeed[customSource] == nil {
+		m.byFeed[customSource] = make(map[string]bool)
+	}
+	m.byFeed[customSource][normalized] = true
+	return nil
+}
+
+// Remove deletes a single domain added via Add or loaded from a feed.
+func (m *Matcher) Remove(domain string) error {
+	normalized, err := Normalize(domain)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, set := range m.byFeed {
+		delete(set, normalized)
+	}
+	return nil
+}
+
+// Len returns the number of distinct domains currently loaded across all
+// feeds and custom entries.
+func (m *Matcher) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	seen := make(map[string]bool)
+	for _, set := range m.byFeed {
+		for d := range set {
+			seen[d] = true
+		}
+	}
+	return len(seen)
+}
+
+// replaceFeed atomically swaps in the domains parsed from a single feed,
+// keyed by source URL so one feed's refresh never clobbers another's
+// entries (or the runtime-added custom ones, kept under customSource).
+func (m *Matcher) replaceFeed(source string, domains map[string]bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byFeed[source] = domains
+}
+
+// Normalize lowercases, strips a trailing dot, converts IDN labels to ASCII
+// (punycode), and reduces the domain to its registrable eTLD+1 so that
+// "mail.scam-bank.co.uk" and "scam-bank.co.uk" dedupe to the same entry.
+func Normalize(domain string) (string, error) {
+	domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+	if domain == "" {
+		return "", fmt.Errorf("blocklist: empty domain")
+	}
+
+	ascii, err := idna.ToASCII(domain)
+	if err != nil {
+		return "", fmt.Errorf("blocklist: invalid domain %q: %w", domain, err)
+	}
+
+	etld1, err := publicsuffix.EffectiveTLDPlusOne(ascii)
+	if err != nil {
+		// Not under a known public suffix (e.g. a bare single-label host
+		// in a hosts file, or an internal test domain) - keep it as-is.
+		return ascii, nil
+	}
+	return etld1, nil
+}
+
+// parseFeed reads a hosts file, a plain domain-per-line list, or an Adblock
+// Plus rule list and returns the domains it names. Format is auto-detected
+// per line so a single feed may mix styles.
+func parseFeed(r io.Reader) []string {
+	var domains []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "||") {
+			// Adblock Plus: "||domain^" or "||domain^$third-party" etc.
+			rule := strings.TrimPrefix(line, "||")
+			if end := strings.IndexAny(rule, "^$/"); end != -1 {
+				rule = rule[:end]
+			}
+			if rule != "" {
+				domains = append(domains, rule)
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 0:
+			continue
+		case 1:
+			// Plain domain-per-line list.
+			domains = append(domains, fields[0])
+		default:
+			// Hosts format: "0.0.0.0 domain" / "127.0.0.1 domain alias...".
+			for _, host := range fields[1:] {
+				if host == "" || strings.HasPrefix(host, "#") {
+					break
+				}
+				domains = append(domains, host)
+			}
+		}
+	}
+	return domains
+}
+
+// Feed is one remote blocklist source, tracked for conditional refresh.
+type Feed struct {
+	URL          string
+	lastETag     string
+	lastModified string
+}
+
+// Updater periodically re-fetches a set of Feeds into a Matcher, using
+// ETag/If-Modified-Since so an unchanged feed doesn't cost a full download
+// every refresh interval.
+type Updater struct {
+	matcher  *Matcher
+	client   *http.Client
+	feeds    []*Feed
+	interval time.Duration
+}
+
+// NewUpdater builds an Updater over the given feed URLs, sharing matcher
+// with the rest of the server.
+func NewUpdater(matcher *Matcher, urls []string, interval time.Duration) *Updater {
+	feeds := make([]*Feed, len(urls))
+	for i, u := range urls {
+		feeds[i] = &Feed{URL: u}
+	}
+	return &Updater{
+		matcher:  matcher,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		feeds:    feeds,
+		interval: interval,
+	}
+}
+
+// Run blocks, refreshing every feed immediately and then on interval, until
+// stop is closed. Intended to be launched in its own goroutine.
+func (u *Updater) Run(stop <-chan struct{}) {
+	u.RefreshAll()
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			u.RefreshAll()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// RefreshAll re-fetches every configured feed, skipping ones that answer
+// 304 Not Modified.
+func (u *Updater) RefreshAll() {
+	for _, feed := range u.feeds {
+		if err := u.refreshFeed(feed); err != nil {
+			fmt.Printf("blocklist: failed to refresh %s: %v\n", feed.URL, err)
+		}
+	}
+}
+
+func (u *Updater) refreshFeed(feed *Feed) error {
+	req, err := http.NewRequest(http.MethodGet, feed.URL, nil)
+	if err != nil {
+		return err
+	}
+	if feed.lastETag != "" {
+		req.Header.Set("If-None-Match", feed.lastETag)
+	}
+	if feed.lastModified != "" {
+		req.Header.Set("If-Modified-Since", feed.lastModified)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	raw := parseFeed(resp.Body)
+	domains := make(map[string]bool, len(raw))
+	for _, d := range raw {
+		normalized, err := Normalize(d)
+		if err != nil {
+			continue
+		}
+		domains[normalized] = true
+	}
+
+	feed.lastETag = resp.Header.Get("ETag")
+	feed.lastModified = resp.Header.Get("Last-Modified")
+
+	u.matcher.replaceFeed(feed.URL, domains)
+	return nil
+}
@@ -0,0 +1,137 @@
+// Package bodydecode walks a MIME message body - multipart/* parts,
+// nested message/rfc822 sub-messages - decoding each leaf per its
+// Content-Transfer-Encoding and declared charset into UTF-8 text, using
+// the same charset resolution headerdecode applies to headers.
+package bodydecode
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+
+	"github.com/jonasboos/phishing-live/headerdecode"
+)
+
+// DecodedPart is one part of a decoded MIME body: a leaf has UTF8Body set,
+// a multipart/* or message/rfc822 container has Parts set instead.
+type DecodedPart struct {
+	MediaType string              `json:"media_type"`
+	Params    map[string]string   `json:"params,omitempty"`
+	Headers   map[string][]string `json:"headers,omitempty"`
+	UTF8Body  string              `json:"utf8_body,omitempty"`
+	Parts     []DecodedPart       `json:"parts,omitempty"`
+}
+
+// Decode parses a message body under the given top-level Content-Type
+// header value (e.g. "multipart/mixed; boundary=...") and decodes every
+// part it contains.
+func Decode(contentType string, body io.Reader) (DecodedPart, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return DecodedPart{}, fmt.Errorf("bodydecode: %w", err)
+	}
+	header := textproto.MIMEHeader{"Content-Type": {contentType}}
+	return decodePart(header, mediaType, params, body)
+}
+
+// decodePart decodes one part already split out of its parent (or the
+// top-level body, when called from Decode), dispatching on mediaType.
+func decodePart(header textproto.MIMEHeader, mediaType string, params map[string]string, body io.Reader) (DecodedPart, error) {
+	part := DecodedPart{MediaType: mediaType, Params: params, Headers: map[string][]string(header)}
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		boundary := params["boundary"]
+		if boundary == "" {
+			return part, fmt.Errorf("bodydecode: multipart %s missing boundary", mediaType)
+		}
+		mr := multipart.NewReader(body, boundary)
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return part, fmt.Errorf("bodydecode: %w", err)
+			}
+			childType, childParams, err := mime.ParseMediaType(p.Header.Get("Content-Type"))
+			if err != nil {
+				childType, childParams = "text/plain", map[string]string{}
+			}
+			child, err := decodePart(p.Header, childType, childParams, p)
+			if err != nil {
+				return part, err
+			}
+			part.Parts = append(part.Parts, child)
+		}
+
+	case mediaType == "message/rfc822":
+		sub, err := decodeRFC822(body)
+		if err != nil {
+			return part, err
+		}
+		part.Parts = append(part.Parts, sub)
+
+	default:
+		decoded, err := decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body)
+		if err != nil {
+			return part, err
+		}
+		part.UTF8Body = toUTF8(decoded, params["charset"])
+	}
+
+	return part, nil
+}
+
+// decodeRFC822 reads a nested message/rfc822 sub-message: its own header
+// block followed by its own body, recursing back into decodePart.
+func decodeRFC822(body io.Reader) (DecodedPart, error) {
+	tp := textproto.NewReader(bufio.NewReader(body))
+	header, err := tp.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return DecodedPart{}, fmt.Errorf("bodydecode: rfc822 sub-message: %w", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	return decodePart(header, mediaType, params, tp.R)
+}
+
+// decodeTransferEncoding decodes r per the Content-Transfer-Encoding value
+// cte; an empty or unrecognized value is treated as identity (7bit/8bit).
+func decodeTransferEncoding(cte string, r io.Reader) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(cte)) {
+	case "quoted-printable":
+		return io.ReadAll(quotedprintable.NewReader(r))
+	case "base64":
+		return io.ReadAll(base64.NewDecoder(base64.StdEncoding, r))
+	default:
+		return io.ReadAll(r)
+	}
+}
+
+// toUTF8 converts data from charset to UTF-8 via headerdecode's charset
+// table, falling back to returning data as-is when charset is empty,
+// already UTF-8/ASCII, or unrecognized. An unrecognized charset is not
+// decoded as Latin-1 here, unlike in headerdecode.DecodeHeader.
+func toUTF8(data []byte, charset string) string {
+	if charset == "" || strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "us-ascii") {
+		return string(data)
+	}
+	enc, ok := headerdecode.Charset(charset)
+	if !ok {
+		return string(data)
+	}
+	out, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(out)
+}
@@ -0,0 +1,151 @@
+// Package cardinality implements a HyperLogLog sketch for estimating the
+// number of distinct items in a stream using bounded memory - used by the
+// trainer's -approx mode to report an estimated per-class vocabulary size
+// next to the exact count from its map[string]int tables.
+package cardinality
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// defaultPrecision of 14 gives 2^14 = 16384 registers, a standard HyperLogLog
+// tradeoff (~0.8% standard error) between memory and accuracy.
+const defaultPrecision = 14
+
+// Sketch is a HyperLogLog cardinality estimator. The zero value is not
+// usable - construct with New.
+type Sketch struct {
+	precision uint
+	registers []uint8
+}
+
+// New returns a Sketch with the default precision (2^14 registers).
+func New() *Sketch {
+	return NewWithPrecision(defaultPrecision)
+}
+
+// NewWithPrecision returns a Sketch using 2^precision registers; precision
+// must be between 4 and 18.
+func NewWithPrecision(precision uint) *Sketch {
+	if precision < 4 || precision > 18 {
+		precision = defaultPrecision
+	}
+	return &Sketch{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}
+}
+
+// Add records a single occurrence of item in the stream.
+func (s *Sketch) Add(item string) {
+	h := hash64(item)
+
+	idx := h >> (64 - s.precision)
+	// The remaining bits (with the index bits cleared out) determine the
+	// register's rank: how many leading zeros before the first 1 bit.
+	rest := h<<s.precision | (1 << (s.precision - 1))
+	rank := uint8(leadingZeros64(rest) + 1)
+
+	if rank > s.registers[idx] {
+		s.registers[idx] = rank
+	}
+}
+
+// Estimate returns the approximate number of distinct items added so far.
+func (s *Sketch) Estimate() uint64 {
+	m := float64(len(s.registers))
+	alpha := alphaFor(m)
+
+	var sumInv float64
+	var zeros int
+	for _, r := range s.registers {
+		sumInv += 1.0 / math.Pow(2, float64(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	raw := alpha * m * m / sumInv
+
+	// Small-range correction: fall back to linear counting when many
+	// registers are still empty, which HyperLogLog's raw estimate
+	// underestimates badly.
+	if raw <= 2.5*m && zeros > 0 {
+		return uint64(m * math.Log(m/float64(zeros)))
+	}
+	return uint64(raw)
+}
+
+// Merge folds other's registers into s (a union of the two streams),
+// requiring both sketches to share the same precision.
+func (s *Sketch) Merge(other *Sketch) error {
+	if other == nil {
+		return nil
+	}
+	if s.precision != other.precision {
+		return fmt.Errorf("cardinality: cannot merge sketches of precision %d and %d", s.precision, other.precision)
+	}
+	for i, r := range other.registers {
+		if r > s.registers[i] {
+			s.registers[i] = r
+		}
+	}
+	return nil
+}
+
+// MarshalBinary encodes the sketch as precision followed by its raw
+// register bytes, for persisting a per-word or per-class sketch to disk.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 1+len(s.registers))
+	buf[0] = byte(s.precision)
+	copy(buf[1:], s.registers)
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a sketch previously written by MarshalBinary.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return fmt.Errorf("cardinality: empty sketch data")
+	}
+	precision := uint(data[0])
+	expected := 1 << precision
+	if len(data)-1 != expected {
+		return fmt.Errorf("cardinality: expected %d register bytes for precision %d, got %d", expected, precision, len(data)-1)
+	}
+	s.precision = precision
+	s.registers = make([]uint8, expected)
+	copy(s.registers, data[1:])
+	return nil
+}
+
+func alphaFor(m float64) float64 {
+	switch {
+	case m == 16:
+		return 0.673
+	case m == 32:
+		return 0.697
+	case m == 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/m)
+	}
+}
+
+func hash64(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func leadingZeros64(x uint64) int {
+	if x == 0 {
+		return 64
+	}
+	n := 0
+	for mask := uint64(1) << 63; mask&x == 0; mask >>= 1 {
+		n++
+	}
+	return n
+}
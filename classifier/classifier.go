@@ -0,0 +1,192 @@
+// Package classifier implements a Multinomial Naive Bayes text classifier
+// that supplements the heuristic scoring in cmd/server with a model learned
+// from previously labeled emails.
+package classifier
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Label identifies the class a training email belongs to.
+type Label string
+
+const (
+	Ham   Label = "Ham"
+	Phish Label = "Phish"
+)
+
+var (
+	bucketHam      = []byte("Wordlists/Ham")
+	bucketPhish    = []byte("Wordlists/Phish")
+	bucketCounters = []byte("Counters")
+
+	// Same tokenizer the heuristic analyzer already uses, so both signals
+	// are computed from identical tokens.
+	tokenRegex = regexp.MustCompile(`[\p{L}]{3,}`)
+	urlFrag    = regexp.MustCompile(`^(https?|www|href|src)$`)
+)
+
+// Classifier is a BoltDB-backed Naive Bayes model.
+type Classifier struct {
+	db *bolt.DB
+}
+
+// Open creates or opens the BoltDB file at path, initializing the buckets
+// used to store per-word counts and class totals.
+func Open(path string) (*Classifier, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("classifier: open %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{bucketHam, bucketPhish, bucketCounters} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Classifier{db: db}, nil
+}
+
+// Close releases the underlying BoltDB handle.
+func (c *Classifier) Close() error {
+	return c.db.Close()
+}
+
+// tokenize extracts the same lowercase word tokens the heuristic analyzer
+// counts, dropping overlong tokens and obvious URL fragments.
+func tokenize(text string) []string {
+	raw := tokenRegex.FindAllString(strings.ToLower(text), -1)
+	tokens := make([]string, 0, len(raw))
+	for _, w := range raw {
+		if len(w) > 20 || urlFrag.MatchString(w) {
+			continue
+		}
+		tokens = append(tokens, w)
+	}
+	return tokens
+}
+
+// Train updates the per-word counts for the given class from a single
+// email's subject+body text.
+func (c *Classifier) Train(text string, label Label) error {
+	bucketName := bucketForLabel(label)
+	tokens := tokenize(text)
+
+	return c.db.Update(func(tx *bolt.Tx) error {
+		words := tx.Bucket(bucketName)
+		counters := tx.Bucket(bucketCounters)
+
+		for _, w := range tokens {
+			count := getUint64(words, []byte(w))
+			if err := putUint64(words, []byte(w), count+1); err != nil {
+				return err
+			}
+		}
+
+		emails := getUint64(counters, []byte(string(label)+"/Emails"))
+		if err := putUint64(counters, []byte(string(label)+"/Emails"), emails+1); err != nil {
+			return err
+		}
+		total := getUint64(counters, []byte(string(label)+"/TotalWords"))
+		return putUint64(counters, []byte(string(label)+"/TotalWords"), total+uint64(len(tokens)))
+	})
+}
+
+func bucketForLabel(label Label) []byte {
+	if label == Phish {
+		return bucketPhish
+	}
+	return bucketHam
+}
+
+// Classify scores text against the trained model and returns the
+// probability that it is phishing, in [0, 1].
+//
+// Per-word likelihoods use Laplace smoothing:
+//
+//	P(W|Phish) = (count(W,Phish)+1) / (totalWords(Phish)+|V|)
+//
+// Words absent from both classes are skipped rather than smoothed, so they
+// don't dilute the signal. Everything is accumulated in log-space to avoid
+// underflow on long emails.
+func (c *Classifier) Classify(text string) (probPhish float64, err error) {
+	tokens := tokenize(text)
+
+	err = c.db.View(func(tx *bolt.Tx) error {
+		ham := tx.Bucket(bucketHam)
+		phish := tx.Bucket(bucketPhish)
+		counters := tx.Bucket(bucketCounters)
+
+		hamEmails := float64(getUint64(counters, []byte("Ham/Emails")))
+		phishEmails := float64(getUint64(counters, []byte("Phish/Emails")))
+		if hamEmails == 0 || phishEmails == 0 {
+			return fmt.Errorf("classifier: no trained data for one or both classes")
+		}
+
+		hamTotal := float64(getUint64(counters, []byte("Ham/TotalWords")))
+		phishTotal := float64(getUint64(counters, []byte("Phish/TotalWords")))
+		vocab := float64(vocabularySize(ham, phish))
+
+		logHam := math.Log(hamEmails / (hamEmails + phishEmails))
+		logPhish := math.Log(phishEmails / (hamEmails + phishEmails))
+
+		for _, w := range tokens {
+			key := []byte(w)
+			hamCount := getUint64(ham, key)
+			phishCount := getUint64(phish, key)
+			if hamCount == 0 && phishCount == 0 {
+				continue
+			}
+			logHam += math.Log((float64(hamCount) + 1) / (hamTotal + vocab))
+			logPhish += math.Log((float64(phishCount) + 1) / (phishTotal + vocab))
+		}
+
+		probPhish = 1 / (1 + math.Exp(logHam-logPhish))
+		return nil
+	})
+
+	return probPhish, err
+}
+
+// vocabularySize returns the number of distinct words seen across both
+// classes, used as |V| in the Laplace smoothing term.
+func vocabularySize(ham, phish *bolt.Bucket) int {
+	seen := make(map[string]struct{})
+	c := ham.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		seen[string(k)] = struct{}{}
+	}
+	c = phish.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		seen[string(k)] = struct{}{}
+	}
+	return len(seen)
+}
+
+func getUint64(b *bolt.Bucket, key []byte) uint64 {
+	v := b.Get(key)
+	if v == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(v)
+}
+
+func putUint64(b *bolt.Bucket, key []byte, value uint64) error {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, value)
+	return b.Put(key, buf)
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// LinkArtifact describes a single hyperlink, image source, or form action
+// harvested from an email's HTML body.
+type LinkArtifact struct {
+	DisplayText         string  `json:"display_text"`
+	TargetURL           string  `json:"target_url"`
+	TargetDomain        string  `json:"target_domain"`
+	MismatchWithDisplay bool    `json:"mismatch_with_display"`
+	IsShortener         bool    `json:"is_shortener"`
+	IsPunycode          bool    `json:"is_punycode"`
+	IsIPLiteral         bool    `json:"is_ip_literal"`
+	HasUserinfo         bool    `json:"has_userinfo"`
+	SubdomainDepth      int     `json:"subdomain_depth"`
+	HasSuspiciousTLD    bool    `json:"has_suspicious_tld"`
+	DomainTrustScore    string  `json:"domain_trust_score"`
+	BlacklistStatus     string  `json:"blacklist_status"`
+	RiskScore           float64 `json:"risk_score"`
+}
+
+// AttachmentArtifact describes a non-text MIME part found in the message.
+type AttachmentArtifact struct {
+	Filename        string `json:"filename"`
+	MediaType       string `json:"media_type"`
+	Size            int    `json:"size"`
+	SHA256          string `json:"sha256"`
+	IsDangerous     bool   `json:"is_dangerous"`
+	DoubleExtension bool   `json:"double_extension"`
+}
+
+var shortenerDomains = map[string]bool{
+	"bit.ly": true, "t.co": true, "tinyurl.com": true, "goo.gl": true,
+	"ow.ly": true, "is.gd": true, "buff.ly": true, "rebrand.ly": true,
+}
+
+// suspiciousTLDs are top-level domains disproportionately used for
+// throwaway phishing infrastructure because registration is cheap and
+// unmoderated.
+var suspiciousTLDs = map[string]bool{
+	".zip": true, ".mov": true, ".top": true, ".xyz": true,
+	".tk": true, ".gq": true, ".work": true, ".click": true,
+}
+
+var dangerousExtensions = map[string]bool{
+	".exe": true, ".scr": true, ".js": true, ".hta": true, ".iso": true,
+	".lnk": true, ".bat": true, ".cmd": true, ".vbs": true, ".docm": true,
+	".xlsm": true, ".pptm": true,
+}
+
+// benignExtensions are the common document/media extensions phishing kits
+// disguise a double-extension payload behind, e.g. "invoice.pdf.exe".
+var benignExtensions = map[string]bool{
+	".pdf": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true,
+	".ppt": true, ".pptx": true, ".txt": true, ".jpg": true, ".jpeg": true,
+	".png": true, ".gif": true, ".zip": true,
+}
+
+// extractLinks walks the parsed HTML body and records every <a href>,
+// <img src>, and <form action>, flagging anchor text that looks like a URL
+// or domain but disagrees with where the link actually goes.
+func extractLinks(htmlBody string) []LinkArtifact {
+	doc, err := html.Parse(strings.NewReader(htmlBody))
+	if err != nil {
+		return nil
+	}
+
+	var links []LinkArtifact
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "a":
+				if href, ok := attr(n, "href"); ok {
+					links = append(links, buildLinkArtifact(href, textContent(n)))
+				}
+			case "img":
+				if src, ok := attr(n, "src"); ok {
+					links = append(links, buildLinkArtifact(src, ""))
+				}
+			case "form":
+				if action, ok := attr(n, "action"); ok {
+					links = append(links, buildLinkArtifact(action, ""))
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return links
+}
+
+func buildLinkArtifact(target, displayText string) LinkArtifact {
+	domain := linkDomain(target)
+	link := LinkArtifact{
+		DisplayText:      strings.TrimSpace(displayText),
+		TargetURL:        target,
+		TargetDomain:     domain,
+		IsShortener:      shortenerDomains[domain],
+		IsPunycode:       strings.Contains(domain, "xn--"),
+		IsIPLiteral:      isIPLiteralHost(domain),
+		SubdomainDepth:   subdomainDepth(domain),
+		HasSuspiciousTLD: hasSuspiciousTLD(domain),
+	}
+
+	if u, err := url.Parse(target); err == nil {
+		link.HasUserinfo = u.User != nil
+	}
+
+	// A mismatch fires when the visible text itself looks like a URL or a
+	// bare domain, but points somewhere other than the href.
+	displayDomain := linkDomain(link.DisplayText)
+	if displayDomain != "" && displayDomain != domain {
+		link.MismatchWithDisplay = true
+	}
+
+	if domain != "" {
+		link.DomainTrustScore = checkDomainTrust(domain)
+		link.BlacklistStatus = checkBlacklist(domain)
+	}
+
+	link.RiskScore = linkRiskScore(link)
+	return link
+}
+
+// linkRiskScore weights the individual per-link signals into a single 0-100
+// score, mirroring how analyzeEmail's ScoreBreakdown weights its own
+// signals rather than just counting boolean flags.
+func linkRiskScore(link LinkArtifact) float64 {
+	var score float64
+	if link.MismatchWithDisplay {
+		score += 25
+	}
+	if link.IsShortener {
+		score += 10
+	}
+	if link.IsPunycode {
+		score += 20
+	}
+	if link.IsIPLiteral {
+		score += 30
+	}
+	if link.HasUserinfo {
+		score += 25
+	}
+	if link.SubdomainDepth > 3 {
+		score += 10
+	}
+	if link.HasSuspiciousTLD {
+		score += 15
+	}
+	if link.BlacklistStatus == "Listed" {
+		score += 40
+	}
+	if score > 100 {
+		score = 100
+	}
+	return score
+}
+
+// isIPLiteralHost reports whether domain is actually a raw IPv4/IPv6
+// address rather than a hostname - a common way phishing links dodge
+// domain-reputation checks entirely.
+func isIPLiteralHost(domain string) bool {
+	if domain == "" {
+		return false
+	}
+	return net.ParseIP(strings.Trim(domain, "[]")) != nil
+}
+
+// subdomainDepth counts labels beyond the registrable domain (e.g.
+// "a.b.c.paypal.com" has depth 3), the same last-two-labels approximation
+// organizationalDomain uses elsewhere in this package.
+func subdomainDepth(domain string) int {
+	labels := strings.Split(domain, ".")
+	if len(labels) <= 2 {
+		return 0
+	}
+	return len(labels) - 2
+}
+
+func hasSuspiciousTLD(domain string) bool {
+	for tld := range suspiciousTLDs {
+		if strings.HasSuffix(domain, tld) {
+			return true
+		}
+	}
+	return false
+}
+
+func linkDomain(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		// Fall back to treating bare text like "paypal.com/login" as a domain.
+		if strings.Contains(raw, ".") && !strings.ContainsAny(raw, " \t\n") {
+			host := strings.SplitN(raw, "/", 2)[0]
+			return strings.ToLower(host)
+		}
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// classifyAttachment inspects a filename and content, flagging dangerous or
+// double extensions. Size and SHA256 are computed from the already-decoded
+// bytes.
+func classifyAttachment(filename, mediaType string, content []byte) AttachmentArtifact {
+	sum := sha256.Sum256(content)
+	art := AttachmentArtifact{
+		Filename:  filename,
+		MediaType: mediaType,
+		Size:      len(content),
+		SHA256:    hex.EncodeToString(sum[:]),
+	}
+
+	lower := strings.ToLower(filename)
+	ext := filepathExt(lower)
+	if dangerousExtensions[ext] {
+		art.IsDangerous = true
+	}
+
+	// Double extension: e.g. "invoice.pdf.exe" - a benign document/media
+	// extension immediately followed by an executable one. Flagging on dot
+	// count alone misfires on ordinary filenames like "Q3.final.report.pdf",
+	// so this only fires on that specific benign-then-executable pattern.
+	parts := strings.Split(lower, ".")
+	if len(parts) > 2 && dangerousExtensions[ext] && benignExtensions["."+parts[len(parts)-2]] {
+		art.DoubleExtension = true
+	}
+
+	if isMacroEnabledOfficeType(mediaType) {
+		art.IsDangerous = true
+	}
+
+	return art
+}
+
+func filepathExt(name string) string {
+	idx := strings.LastIndex(name, ".")
+	if idx == -1 {
+		return ""
+	}
+	return name[idx:]
+}
+
+func isMacroEnabledOfficeType(mediaType string) bool {
+	switch mediaType {
+	case "application/vnd.ms-word.document.macroenabled.12",
+		"application/vnd.ms-excel.sheet.macroenabled.12",
+		"application/vnd.ms-powerpoint.presentation.macroenabled.12":
+		return true
+	}
+	return false
+}
+
+// isAttachmentPart reports whether a MIME part should be treated as an
+// attachment: an explicit Content-Disposition: attachment, or any
+// non-text/non-multipart media type with a filename.
+func isAttachmentPart(contentDisposition, mediaType string, params map[string]string) (filename string, isAttachment bool) {
+	_, dispParams, _ := mime.ParseMediaType(contentDisposition)
+	if dispParams != nil && dispParams["filename"] != "" {
+		filename = dispParams["filename"]
+	} else if params["name"] != "" {
+		filename = params["name"]
+	}
+
+	disp := strings.ToLower(strings.TrimSpace(strings.SplitN(contentDisposition, ";", 2)[0]))
+	if disp == "attachment" {
+		return filename, true
+	}
+	if filename != "" && !strings.HasPrefix(mediaType, "text/") && !strings.HasPrefix(mediaType, "multipart/") {
+		return filename, true
+	}
+	return filename, false
+}
+
+// readAllPart drains a part into memory; used for attachments where we need the
+// full content to hash and size.
+func readAllPart(r io.Reader) []byte {
+	b, _ := io.ReadAll(r)
+	return b
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// parseDKIMSignatureDomain pulls the d= (signing domain) tag out of the
+// DKIM-Signature header, the way dmarc.parseTagValues reads DMARC TXT
+// records - DKIM-Signature uses the same "tag=value;" grammar (RFC 6376).
+func parseDKIMSignatureDomain(header mail.Header) (string, bool) {
+	raw := header.Get("DKIM-Signature")
+	if raw == "" {
+		return "", false
+	}
+
+	for _, part := range strings.Split(raw, ";") {
+		part = strings.TrimSpace(part)
+		tag, value, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+		if strings.TrimSpace(tag) == "d" {
+			return strings.ToLower(strings.TrimSpace(value)), true
+		}
+	}
+	return "", false
+}
+
+// hasARCSeal reports whether the message passed through an ARC-aware relay,
+// which stamps ARC-Seal (and the ARC-Authentication-Results already read by
+// parseForwardedAuth) on forwarded mail.
+func hasARCSeal(header mail.Header) bool {
+	return header.Get("ARC-Seal") != ""
+}
+
+// organizationalDomain returns the last two labels of domain (e.g.
+// "mail.paypal.com" -> "paypal.com"), a cheap approximation of DMARC
+// relaxed-mode alignment that's good enough for the two-label brand
+// domains this allowlist already deals in.
+func organizationalDomain(domain string) string {
+	labels := strings.Split(domain, ".")
+	if len(labels) <= 2 {
+		return domain
+	}
+	return strings.Join(labels[len(labels)-2:], ".")
+}
+
+// domainsAligned implements DMARC-style alignment between a message's From
+// domain and a second domain (DKIM d= or Return-Path): strict alignment
+// requires an exact match, relaxed alignment only requires the same
+// organizational domain.
+func domainsAligned(fromDomain, other string, strict bool) bool {
+	if fromDomain == "" || other == "" {
+		return false
+	}
+	if strict {
+		return fromDomain == other
+	}
+	return organizationalDomain(fromDomain) == organizationalDomain(other)
+}
+
+// downgradeForAuthMismatch re-evaluates a "Trustworthy" verdict for a
+// well-known brand: if the message fails SPF/DKIM outright, its DKIM
+// signature doesn't align with the From domain, or authentication is
+// missing entirely (no DKIM signature and no passing SPF/DMARC result),
+// the brand name in the From header isn't backed by authentication and the
+// verdict is downgraded to "Suspicious" rather than left as a false sense
+// of safety. A spoofed From with zero auth headers - the single most
+// common phishing pattern - would otherwise sail through unchanged.
+func downgradeForAuthMismatch(trustScore, spfStatus, dkimStatus, dmarcStatus string, dkimPresent, dkimAligned bool) string {
+	if trustScore != "Trustworthy" {
+		return trustScore
+	}
+	if spfStatus == "fail" || dkimStatus == "fail" {
+		return "Suspicious"
+	}
+	if dkimPresent && !dkimAligned {
+		return "Suspicious"
+	}
+	if !dkimPresent && spfStatus != "pass" && dmarcStatus != "pass" {
+		return "Suspicious"
+	}
+	return trustScore
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// runScan implements the `scan <path>` subcommand: it walks a Maildir tree
+// or splits a multi-message mbox file, analyzes every message with a worker
+// pool, and persists each result into the history store so re-scans are
+// idempotent.
+func runScan(path string) {
+	if path == "" {
+		fmt.Println("Usage: server scan <maildir-or-mbox-path>")
+		os.Exit(1)
+	}
+
+	store, err := openHistoryStore()
+	if err != nil {
+		fmt.Printf("Error opening history store: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	messages, err := loadMessages(path)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Found %d messages under %s\n", len(messages), path)
+
+	summary := scanMessages(store, messages)
+	printScanSummary(summary)
+}
+
+// rawMessage is a single message pulled from a Maildir or mbox, still
+// carrying its source location for the history record.
+type rawMessage struct {
+	SourcePath string
+	Content    string
+}
+
+// loadMessages dispatches to the Maildir walker or the mbox splitter based
+// on what path points at.
+func loadMessages(path string) ([]rawMessage, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		if isMaildir(path) {
+			return walkMaildir(path)
+		}
+		return nil, fmt.Errorf("%s is a directory but not a Maildir (missing cur/new/tmp)", path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(string(content), "From ") {
+		return splitMbox(path, string(content)), nil
+	}
+
+	// A single .eml file.
+	return []rawMessage{{SourcePath: path, Content: string(content)}}, nil
+}
+
+func isMaildir(dir string) bool {
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+func walkMaildir(dir string) ([]rawMessage, error) {
+	var messages []rawMessage
+	for _, sub := range []string{"cur", "new"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, sub, entry.Name())
+			content, err := os.ReadFile(path)
+			if err != nil {
+				fmt.Printf("Warning: skipping %s: %v\n", path, err)
+				continue
+			}
+			messages = append(messages, rawMessage{SourcePath: path, Content: string(content)})
+		}
+	}
+	return messages, nil
+}
+
+// splitMbox splits an mbox file into individual messages on "From " line
+// boundaries. handleAnalyze, which only ever sees a single message, uses
+// stripMboxPrefix instead.
+func splitMbox(path, content string) []rawMessage {
+	var messages []rawMessage
+	var current strings.Builder
+	atBoundary := true
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if atBoundary && strings.HasPrefix(line, "From ") {
+			if current.Len() > 0 {
+				messages = append(messages, rawMessage{SourcePath: path, Content: current.String()})
+				current.Reset()
+			}
+			atBoundary = false
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		atBoundary = line == ""
+	}
+	if current.Len() > 0 {
+		messages = append(messages, rawMessage{SourcePath: path, Content: current.String()})
+	}
+	return messages
+}
+
+// scanSummary aggregates counts across a batch scan for the end-of-run
+// report.
+type scanSummary struct {
+	Analyzed     int
+	Skipped      int
+	AlreadySeen  int
+	VerdictCount map[string]int
+	DomainCount  map[string]int
+	mu           sync.Mutex
+}
+
+func newScanSummary() *scanSummary {
+	return &scanSummary{
+		VerdictCount: make(map[string]int),
+		DomainCount:  make(map[string]int),
+	}
+}
+
+// scanMessages analyzes every message in a worker pool sized to
+// runtime.NumCPU() and persists each result keyed by the SHA-256 of the raw
+// message, so a re-scan of the same mailbox is a no-op for messages already
+// recorded.
+func scanMessages(store *historyStore, messages []rawMessage) *scanSummary {
+	summary := newScanSummary()
+	jobs := make(chan rawMessage)
+
+	var wg sync.WaitGroup
+	workers := runtime.NumCPU()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for raw := range jobs {
+				analyzeAndStore(store, raw, summary)
+			}
+		}()
+	}
+
+	for _, raw := range messages {
+		jobs <- raw
+	}
+	close(jobs)
+	wg.Wait()
+
+	return summary
+}
+
+func analyzeAndStore(store *historyStore, raw rawMessage, summary *scanSummary) {
+	sum := sha256.Sum256([]byte(raw.Content))
+	id := hex.EncodeToString(sum[:])
+
+	if store.Exists(id) {
+		summary.mu.Lock()
+		summary.AlreadySeen++
+		summary.mu.Unlock()
+		return
+	}
+
+	content := stripMboxPrefix(raw.Content)
+
+	msg, err := mail.ReadMessage(strings.NewReader(content))
+	if err != nil {
+		summary.mu.Lock()
+		summary.Skipped++
+		summary.mu.Unlock()
+		return
+	}
+
+	body, attachments := extractEmailBodyAndAttachments(msg)
+	result := analyzeEmail(filepath.Base(raw.SourcePath), msg, body, attachments, content)
+
+	record := historyRecord{
+		ID:         id,
+		SourcePath: raw.SourcePath,
+		MessageID:  msg.Header.Get("Message-Id"),
+		ScannedAt:  time.Now(),
+		Result:     result,
+	}
+	if err := store.Put(record); err != nil {
+		fmt.Printf("Warning: could not store result for %s: %v\n", raw.SourcePath, err)
+	}
+
+	summary.mu.Lock()
+	summary.Analyzed++
+	summary.VerdictCount[verdictBucket(result.ScamProbability)]++
+	summary.DomainCount[result.RiskFactors.Domain]++
+	summary.mu.Unlock()
+}
+
+func verdictBucket(score float64) string {
+	switch {
+	case score >= 70:
+		return "High Risk"
+	case score >= 40:
+		return "Suspicious"
+	default:
+		return "Likely Safe"
+	}
+}
+
+func printScanSummary(s *scanSummary) {
+	fmt.Printf("\nScan complete. Analyzed: %d, Already seen: %d, Skipped (parse errors): %d\n",
+		s.Analyzed, s.AlreadySeen, s.Skipped)
+	fmt.Println("Verdicts:")
+	for verdict, count := range s.VerdictCount {
+		fmt.Printf("  %-12s %d\n", verdict, count)
+	}
+	fmt.Println("Top offending domains:")
+	domains := make([]string, 0, len(s.DomainCount))
+	for domain := range s.DomainCount {
+		if domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	sort.Slice(domains, func(i, j int) bool { return s.DomainCount[domains[i]] > s.DomainCount[domains[j]] })
+	for _, domain := range domains {
+		fmt.Printf("  %-30s %d\n", domain, s.DomainCount[domain])
+	}
+}
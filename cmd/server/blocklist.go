@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jonasboos/phishing-live/blocklist"
+)
+
+// domainBlocklist is the shared in-memory matcher consulted by
+// checkDomainTrust/checkBlacklist before any network lookup.
+var domainBlocklist = blocklist.NewMatcher()
+
+// blocklistUpdater refreshes domainBlocklist from the feed URLs configured
+// via BLOCKLIST_FEED_URLS (comma-separated). Nil if no feeds are configured.
+var blocklistUpdater *blocklist.Updater
+
+// startBlocklistUpdater wires up the configured feeds and launches the
+// background refresh goroutine. A missing BLOCKLIST_FEED_URLS just leaves
+// the matcher empty - custom entries added via the HTTP endpoint still
+// work.
+func startBlocklistUpdater() {
+	raw := os.Getenv("BLOCKLIST_FEED_URLS")
+	if raw == "" {
+		return
+	}
+
+	var urls []string
+	for _, u := range strings.Split(raw, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		return
+	}
+
+	interval := 6 * time.Hour
+	if v := os.Getenv("BLOCKLIST_REFRESH_INTERVAL_MINUTES"); v != "" {
+		if minutes, err := strconv.Atoi(v); err == nil && minutes > 0 {
+			interval = time.Duration(minutes) * time.Minute
+		}
+	}
+
+	blocklistUpdater = blocklist.NewUpdater(domainBlocklist, urls, interval)
+	go blocklistUpdater.Run(nil)
+	fmt.Printf("Blocklist updater started for %d feed(s), refreshing every %s\n", len(urls), interval)
+}
+
+// handleBlocklistReload triggers an immediate re-fetch of every configured
+// feed, bypassing the refresh interval.
+func handleBlocklistReload(w http.ResponseWriter, r *http.Request) {
+	if blocklistUpdater == nil {
+		http.Error(w, "no blocklist feeds configured (set BLOCKLIST_FEED_URLS)", http.StatusServiceUnavailable)
+		return
+	}
+	blocklistUpdater.RefreshAll()
+	fmt.Fprintf(w, "{\"status\":\"reloaded\",\"entries\":%d}", domainBlocklist.Len())
+}
+
+// handleBlocklistEntry adds or removes a single custom domain at runtime:
+// POST /blocklist/entry?domain=evil.example to add, DELETE to remove.
+func handleBlocklistEntry(w http.ResponseWriter, r *http.Request) {
+	domain := r.URL.Query().Get("domain")
+	if domain == "" {
+		http.Error(w, "missing domain query parameter", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch r.Method {
+	case http.MethodPost, http.MethodPut:
+		err = domainBlocklist.Add(domain)
+	case http.MethodDelete:
+		err = domainBlocklist.Remove(domain)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fmt.Fprintf(w, "{\"status\":\"ok\",\"entries\":%d}", domainBlocklist.Len())
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var bucketHistory = []byte("Analyses")
+
+// historyRecord is what gets persisted per analyzed message: the analysis
+// result plus enough provenance to find the original message again.
+type historyRecord struct {
+	ID         string         `json:"id"`
+	SourcePath string         `json:"source_path"`
+	MessageID  string         `json:"message_id"`
+	ScannedAt  time.Time      `json:"scanned_at"`
+	Result     AnalysisResult `json:"result"`
+}
+
+// historyStore is a thin BoltDB wrapper keyed by the SHA-256 of the raw
+// message, so re-scanning a mailbox never creates duplicate entries.
+type historyStore struct {
+	db *bolt.DB
+}
+
+func openHistoryStore() (*historyStore, error) {
+	path := resolvePath("data/history.db")
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("history: open %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketHistory)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &historyStore{db: db}, nil
+}
+
+func (s *historyStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *historyStore) Exists(id string) bool {
+	found := false
+	s.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(bucketHistory).Get([]byte(id)) != nil
+		return nil
+	})
+	return found
+}
+
+func (s *historyStore) Put(record historyRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketHistory).Put([]byte(record.ID), data)
+	})
+}
+
+func (s *historyStore) Get(id string) (historyRecord, bool) {
+	var record historyRecord
+	found := false
+	s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketHistory).Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		if json.Unmarshal(data, &record) == nil {
+			found = true
+		}
+		return nil
+	})
+	return record, found
+}
+
+// historyFilter narrows down the /history listing. From and To bound
+// ScannedAt; either may be left zero to leave that end of the range open.
+type historyFilter struct {
+	MinScore     float64
+	SenderDomain string
+	DMARCFail    bool
+	HasAttach    bool
+	From         time.Time
+	To           time.Time
+}
+
+// List returns every stored record matching the filter, newest-bucket-key
+// first is not guaranteed (BoltDB iterates in key order, i.e. by SHA-256,
+// so results are simply in storage order).
+func (s *historyStore) List(filter historyFilter) []historyRecord {
+	var records []historyRecord
+	s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketHistory).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var record historyRecord
+			if json.Unmarshal(v, &record) != nil {
+				continue
+			}
+			if matchesFilter(record, filter) {
+				records = append(records, record)
+			}
+		}
+		return nil
+	})
+	return records
+}
+
+func matchesFilter(record historyRecord, filter historyFilter) bool {
+	if record.Result.ScamProbability < filter.MinScore {
+		return false
+	}
+	if filter.SenderDomain != "" && record.Result.RiskFactors.Domain != filter.SenderDomain {
+		return false
+	}
+	if filter.DMARCFail && record.Result.RiskFactors.DMARCStatus != "fail" {
+		return false
+	}
+	if filter.HasAttach && len(record.Result.Attachments) == 0 {
+		return false
+	}
+	if !filter.From.IsZero() && record.ScannedAt.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && record.ScannedAt.After(filter.To) {
+		return false
+	}
+	return true
+}
+
+// handleHistory serves GET /history: a filtered list of stored analyses.
+func handleHistory(w http.ResponseWriter, r *http.Request) {
+	store, err := openHistoryStore()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	defer store.Close()
+
+	filter := historyFilter{
+		SenderDomain: r.URL.Query().Get("domain"),
+		DMARCFail:    r.URL.Query().Get("dmarc") == "fail",
+		HasAttach:    r.URL.Query().Get("has_attachments") == "true",
+	}
+	if minScore := r.URL.Query().Get("min_score"); minScore != "" {
+		if v, err := strconv.ParseFloat(minScore, 64); err == nil {
+			filter.MinScore = v
+		}
+	}
+	if from := r.URL.Query().Get("from"); from != "" {
+		if v, err := time.Parse("2006-01-02", from); err == nil {
+			filter.From = v
+		}
+	}
+	if to := r.URL.Query().Get("to"); to != "" {
+		if v, err := time.Parse("2006-01-02", to); err == nil {
+			filter.To = v.Add(24 * time.Hour)
+		}
+	}
+
+	records := store.List(filter)
+
+	t, err := template.ParseFiles("templates/history.html")
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	t.Execute(w, records)
+}
+
+// handleHistoryDetail serves GET /history/{id}: the full analysis for a
+// single stored record, reusing the main index template.
+func handleHistoryDetail(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/history/")
+	if id == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	store, err := openHistoryStore()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	defer store.Close()
+
+	record, ok := store.Get(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	t, err := template.ParseFiles("templates/index.html")
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	data := PageData{
+		TestEmails: getTestEmails(),
+		Analysis:   &record.Result,
+	}
+	t.Execute(w, data)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"html"
@@ -23,6 +24,15 @@ import (
 
 	"github.com/abadojack/whatlanggo"
 	"github.com/bregydoc/gtranslate"
+	"github.com/jonasboos/phishing-live/classifier"
+	"github.com/jonasboos/phishing-live/dmarc"
+	"github.com/jonasboos/phishing-live/emlparse"
+	"github.com/jonasboos/phishing-live/headerdecode"
+	"github.com/jonasboos/phishing-live/homoglyph"
+	"github.com/jonasboos/phishing-live/homograph"
+	"github.com/jonasboos/phishing-live/htmltext"
+	"github.com/jonasboos/phishing-live/rdap"
+	"github.com/jonasboos/phishing-live/reputation"
 )
 
 // --- Structs for Linguistic Data ---
@@ -45,6 +55,9 @@ type LinguisticStats struct {
 // Global stats
 var globalStats LinguisticStats
 
+// Trained Naive Bayes model, loaded once at startup if present.
+var bayesModel *classifier.Classifier
+
 // In-memory store for uploaded files
 var (
 	memoryStore = make(map[string]string)
@@ -60,12 +73,16 @@ type LinguisticTrigger struct {
 
 type RiskFactors struct {
 	// Header Analysis
-	SPFStatus          string   `json:"header_spf_status"`
-	DKIMStatus         string   `json:"header_dkim_status"`
-	DMARCStatus        string   `json:"header_dmarc_status"`
-	FromReturnPathDiff bool     `json:"from_return_path_mismatch"`
-	ReplyToDiff        bool     `json:"reply_to_mismatch"`
-	Suspiciouskeywords []string `json:"suspicious_keywords"`
+	SPFStatus           string               `json:"header_spf_status"`
+	DKIMStatus          string               `json:"header_dkim_status"`
+	DMARCStatus         string               `json:"header_dmarc_status"`
+	AuthResultDetails   []dmarc.MethodResult `json:"auth_result_details"`
+	FromReturnPathDiff  bool                 `json:"from_return_path_mismatch"`
+	ReplyToDiff         bool                 `json:"reply_to_mismatch"`
+	DKIMSignatureDomain string               `json:"dkim_signature_domain"`
+	DKIMAligned         bool                 `json:"dkim_aligned"`
+	HasARCSeal          bool                 `json:"has_arc_seal"`
+	Suspiciouskeywords  []string             `json:"suspicious_keywords"`
 
 	// Active Network/API Checks
 	Domain           string `json:"domain"`
@@ -76,9 +93,39 @@ type RiskFactors struct {
 	BlacklistStatus  string `json:"blacklist_status"`   // "Clean", "Listed", "Error", "Unknown"
 	IsDisposable     bool   `json:"api_is_disposable"`
 
+	// Per-source DNSBL evidence behind BlacklistStatus (zones checked, which
+	// ones matched, and how confident the aggregate verdict is).
+	BlacklistEvidence reputation.Result `json:"blacklist_evidence"`
+
+	// RDAP registration signals for the sender's domain.
+	DomainRegistrar    string `json:"domain_registrar"`
+	DomainAgeDays      int    `json:"domain_age_days"` // -1 if unknown
+	DomainIsNew        bool   `json:"domain_is_new"`   // younger than 30 days
+	DomainPrivacyProxy bool   `json:"domain_privacy_proxy"`
+
+	// DMARC Policy (parsed from the live _dmarc TXT record)
+	DMARCPolicy          string   `json:"dmarc_policy"` // none/quarantine/reject
+	DMARCPercent         int      `json:"dmarc_percent"`
+	DMARCAlignmentStrict bool     `json:"dmarc_alignment_strict"`
+	DMARCReportingURIs   []string `json:"dmarc_reporting_uris"`
+
 	// Linguistic Analysis
 	LinguisticTriggers []LinguisticTrigger `json:"linguistic_triggers"`
 	ShoutingScore      float64             `json:"shouting_score"`
+
+	// Link/Attachment Analysis
+	HasLinkMismatch    bool `json:"has_link_mismatch"`
+	HasDangerousAttach bool `json:"has_dangerous_attachment"`
+
+	// Upstream scanner verdicts already stamped onto the message
+	UpstreamVerdicts []UpstreamVerdict `json:"upstream_verdicts"`
+
+	// emlparse's standalone pass over the raw message: mixed-script/
+	// confusable/invisible-codepoint findings for the From/Reply-To/
+	// Sender domains, plus any malformed encoded-word LenientDecoder had
+	// to repair - a malformed header is itself a phishing-kit signal.
+	HomographFindings  []homograph.Finding        `json:"homograph_findings,omitempty"`
+	HeaderRepairEvents []headerdecode.RepairEvent `json:"header_repair_events,omitempty"`
 }
 
 type ScoreBreakdown struct {
@@ -91,25 +138,33 @@ type ScoreBreakdown struct {
 	DisposablePenalty        float64 `json:"disposable_penalty"`
 	LinguisticPenalty        float64 `json:"linguistic_penalty"`
 	SubjectLinguisticPenalty float64 `json:"subject_linguistic_penalty"`
+	BayesPenalty             float64 `json:"bayes_penalty"`
+	LinkMismatchPenalty      float64 `json:"link_mismatch_penalty"`
+	AttachmentPenalty        float64 `json:"attachment_penalty"`
+	UpstreamPenalty          float64 `json:"upstream_penalty"`
+	HomographPenalty         float64 `json:"homograph_penalty"`
 	TotalScore               float64 `json:"total_score"`
 }
 
 type AnalysisResult struct {
-	FileName        string              `json:"file_name"`
-	DetectedLang    string              `json:"detected_lang"`
-	TranslatedBody  string              `json:"translated_body"`
-	ScamProbability float64             `json:"scam_probability_percent"`
-	SafeProbability float64             `json:"safe_probability_percent"`
-	TechScore       float64             `json:"tech_score"`
-	BodyScore       float64             `json:"body_score"`
-	SubjectScore    float64             `json:"subject_score"`
-	EmailBody       template.HTML       `json:"email_body"` // Highlighted text version (optional/fallback)
-	HTMLBody        template.HTML       `json:"html_body"`  // Authentic HTML for iframe
-	Headers         map[string]string   `json:"headers"`
-	RiskFactors     RiskFactors         `json:"risk_factors"`
-	ScoreBreakdown  ScoreBreakdown      `json:"calculation_details"`
-	BodyTriggers    []LinguisticTrigger `json:"body_triggers"`
-	SubjectTriggers []LinguisticTrigger `json:"subject_triggers"`
+	FileName        string               `json:"file_name"`
+	DetectedLang    string               `json:"detected_lang"`
+	TranslatedBody  string               `json:"translated_body"`
+	ScamProbability float64              `json:"scam_probability_percent"`
+	SafeProbability float64              `json:"safe_probability_percent"`
+	TechScore       float64              `json:"tech_score"`
+	BodyScore       float64              `json:"body_score"`
+	SubjectScore    float64              `json:"subject_score"`
+	BayesScore      float64              `json:"bayes_score"`
+	EmailBody       template.HTML        `json:"email_body"` // Highlighted text version (optional/fallback)
+	HTMLBody        template.HTML        `json:"html_body"`  // Authentic HTML for iframe
+	Headers         map[string]string    `json:"headers"`
+	RiskFactors     RiskFactors          `json:"risk_factors"`
+	ScoreBreakdown  ScoreBreakdown       `json:"calculation_details"`
+	BodyTriggers    []LinguisticTrigger  `json:"body_triggers"`
+	SubjectTriggers []LinguisticTrigger  `json:"subject_triggers"`
+	Links           []LinkArtifact       `json:"links"`
+	Attachments     []AttachmentArtifact `json:"attachments"`
 }
 
 // PageData struct for template
@@ -143,6 +198,23 @@ func loadLinguisticStats() {
 	}
 }
 
+// loadBayesModel opens the trained classifier database if one exists. A
+// missing model is not an error: Classify calls simply report no signal.
+func loadBayesModel() {
+	path := resolvePath("data/classifier.db")
+	if _, err := os.Stat(path); err != nil {
+		log.Printf("Bayes classifier not trained yet (no %s); BayesScore will stay 0. Run `server train <ham-dir>` to train it.", path)
+		return
+	}
+	model, err := classifier.Open(path)
+	if err != nil {
+		log.Printf("Warning: could not open classifier database: %v", err)
+		return
+	}
+	bayesModel = model
+	fmt.Println("Successfully loaded Naive Bayes classifier model.")
+}
+
 func resolvePath(relativePath string) string {
 	// Try direct path (running from root)
 	if _, err := os.Stat(relativePath); err == nil {
@@ -173,7 +245,26 @@ func getTestEmails() []string {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "train" {
+		hamDir := ""
+		if len(os.Args) > 2 {
+			hamDir = os.Args[2]
+		}
+		runTrain(hamDir)
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "scan" {
+		path := ""
+		if len(os.Args) > 2 {
+			path = os.Args[2]
+		}
+		runScan(path)
+		return
+	}
+
 	loadLinguisticStats()
+	loadBayesModel()
+	startBlocklistUpdater()
 
 	// Periodic cleanup of memory store (every 1 hour)
 	go func() {
@@ -212,6 +303,10 @@ func main() {
 	})
 
 	http.HandleFunc("/analyze", handleAnalyze)
+	http.HandleFunc("/history", handleHistory)
+	http.HandleFunc("/history/", handleHistoryDetail)
+	http.HandleFunc("/blocklist/reload", handleBlocklistReload)
+	http.HandleFunc("/blocklist/entry", handleBlocklistEntry)
 
 	fmt.Println("Server started at http://localhost:8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
@@ -221,7 +316,9 @@ func handleAnalyze(w http.ResponseWriter, r *http.Request) {
 	testFile := r.URL.Query().Get("testFile")
 	var msg *mail.Message
 	var bodyString string
+	var rawContent string
 	var filename string
+	var attachments []AttachmentArtifact
 	var err error
 	isTestFile := false
 
@@ -239,14 +336,8 @@ func handleAnalyze(w http.ResponseWriter, r *http.Request) {
 
 		filename = key
 
-		// Check for mbox format (starts with "From ") and strip it
-		if strings.HasPrefix(content, "From ") {
-			fmt.Println("DEBUG: Found mbox prefix in memory content, stripping line.")
-			// Find first newline
-			if idx := strings.Index(content, "\n"); idx != -1 {
-				content = content[idx+1:]
-			}
-		}
+		content = stripMboxPrefix(content)
+		rawContent = content
 
 		msg, err = mail.ReadMessage(strings.NewReader(content))
 		if err != nil {
@@ -256,7 +347,7 @@ func handleAnalyze(w http.ResponseWriter, r *http.Request) {
 		}
 		fmt.Printf("DEBUG: Successfully parsed email from memory. FromHeader: '%s'\n", msg.Header.Get("From"))
 		fmt.Printf("DEBUG: Successfully parsed email from memory. FromHeader: '%s'\n", msg.Header.Get("From"))
-		bodyString = extractEmailBody(msg)
+		bodyString, attachments = extractEmailBodyAndAttachments(msg)
 
 	} else if testFile != "" {
 		// Load from test emails
@@ -273,12 +364,8 @@ func handleAnalyze(w http.ResponseWriter, r *http.Request) {
 		}
 		contentString := string(contentBytes)
 
-		// Check for mbox format (starts with "From ") and strip it
-		if strings.HasPrefix(contentString, "From ") {
-			if idx := strings.Index(contentString, "\n"); idx != -1 {
-				contentString = contentString[idx+1:]
-			}
-		}
+		contentString = stripMboxPrefix(contentString)
+		rawContent = contentString
 
 		msg, err = mail.ReadMessage(strings.NewReader(contentString))
 		if err != nil {
@@ -288,7 +375,7 @@ func handleAnalyze(w http.ResponseWriter, r *http.Request) {
 
 		// mail.ReadMessage parses headers and leaves body in msg.Body
 		// mail.ReadMessage parses headers and leaves body in msg.Body
-		bodyString = extractEmailBody(msg)
+		bodyString, attachments = extractEmailBodyAndAttachments(msg)
 
 	} else {
 		// Handle Upload
@@ -325,7 +412,7 @@ func handleAnalyze(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result := analyzeEmail(filename, msg, bodyString)
+	result := analyzeEmail(filename, msg, bodyString, attachments, rawContent)
 
 	t, err := template.ParseFiles("templates/index.html")
 	if err != nil {
@@ -350,44 +437,84 @@ func handleAnalyze(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func analyzeEmail(filename string, msg *mail.Message, body string) AnalysisResult {
+func analyzeEmail(filename string, msg *mail.Message, body string, attachments []AttachmentArtifact, rawContent string) AnalysisResult {
 	riskFactors := RiskFactors{
-		SPFStatus:   "unknown",
-		DKIMStatus:  "unknown",
-		DMARCStatus: "unknown",
+		SPFStatus:     "unknown",
+		DKIMStatus:    "unknown",
+		DMARCStatus:   "unknown",
+		DomainAgeDays: -1,
 	}
 	breakdown := ScoreBreakdown{}
 
+	// emlparse re-parses the raw message to run its standalone homograph
+	// scan over From/Reply-To/Sender and surface any malformed
+	// encoded-word LenientDecoder had to repair - signals this file's own
+	// header decoding below doesn't compute.
+	if rawContent != "" {
+		if parsed, err := emlparse.Parse(strings.NewReader(rawContent)); err == nil {
+			riskFactors.HomographFindings = parsed.HomographFindings
+			riskFactors.HeaderRepairEvents = parsed.RepairEvents
+			if len(riskFactors.HomographFindings) > 0 {
+				breakdown.HomographPenalty += 25
+			}
+			if len(riskFactors.HeaderRepairEvents) > 0 {
+				breakdown.HomographPenalty += 10
+			}
+		}
+	}
+
 	// --- 1. Header Analysis ---
 
 	authResults := msg.Header.Get("Authentication-Results")
+	var dmarcPass bool
 	if authResults != "" {
-		lowerAuth := strings.ToLower(authResults)
-		if strings.Contains(lowerAuth, "spf=fail") || strings.Contains(lowerAuth, "spf=softfail") {
-			riskFactors.SPFStatus = "fail"
-			breakdown.AuthFailPenalty += 30
-		} else if strings.Contains(lowerAuth, "spf=pass") {
-			riskFactors.SPFStatus = "pass"
-			breakdown.AuthPassBonus += 10
-		}
-		if strings.Contains(lowerAuth, "dkim=fail") {
-			riskFactors.DKIMStatus = "fail"
-			breakdown.AuthFailPenalty += 30
-		} else if strings.Contains(lowerAuth, "dkim=pass") {
-			riskFactors.DKIMStatus = "pass"
-			breakdown.AuthPassBonus += 10
-		}
-		if strings.Contains(lowerAuth, "dmarc=fail") {
-			riskFactors.DMARCStatus = "fail"
-			breakdown.AuthFailPenalty += 20
-		} else if strings.Contains(lowerAuth, "dmarc=pass") {
-			riskFactors.DMARCStatus = "pass"
-			breakdown.AuthPassBonus += 5
+		for _, m := range dmarc.ParseAuthResults(authResults) {
+			riskFactors.AuthResultDetails = append(riskFactors.AuthResultDetails, m)
+			switch m.Mechanism {
+			case "spf":
+				switch m.Result {
+				case "fail", "softfail":
+					riskFactors.SPFStatus = "fail"
+					breakdown.AuthFailPenalty += 30
+				case "pass":
+					riskFactors.SPFStatus = "pass"
+					breakdown.AuthPassBonus += 10
+				}
+			case "dkim":
+				switch m.Result {
+				case "fail":
+					riskFactors.DKIMStatus = "fail"
+					breakdown.AuthFailPenalty += 30
+				case "pass":
+					riskFactors.DKIMStatus = "pass"
+					breakdown.AuthPassBonus += 10
+				}
+			case "dmarc":
+				switch m.Result {
+				case "fail":
+					riskFactors.DMARCStatus = "fail"
+					breakdown.AuthFailPenalty += 20
+				case "pass":
+					riskFactors.DMARCStatus = "pass"
+					dmarcPass = true
+				}
+			}
 		}
 	} else {
 		breakdown.BaseScore += 10
 	}
 
+	// Forwarded mail carries its own Received-SPF and ARC-Authentication-Results
+	// headers, which the top-level Authentication-Results check above ignores.
+	riskFactors.AuthResultDetails = append(riskFactors.AuthResultDetails, parseForwardedAuth(msg.Header)...)
+	riskFactors.HasARCSeal = hasARCSeal(msg.Header)
+
+	dkimDomain, dkimPresent := parseDKIMSignatureDomain(msg.Header)
+	riskFactors.DKIMSignatureDomain = dkimDomain
+
+	// --- 1a. Upstream Scanner Verdicts ---
+	riskFactors.UpstreamVerdicts, breakdown.UpstreamPenalty = collectUpstreamVerdicts(msg.Header)
+
 	from := decodeHeader(msg.Header.Get("From"))
 	returnPath := decodeHeader(msg.Header.Get("Return-Path"))
 	fromAddr := extractEmail(from)
@@ -450,29 +577,102 @@ func analyzeEmail(filename string, msg *mail.Message, body string) AnalysisResul
 		dmarcRecords, _ := net.LookupTXT(dmarcName)
 		fmt.Printf("DEBUG: Found %d TXT records for %s\n", len(dmarcRecords), dmarcName)
 		for _, txt := range dmarcRecords {
-			if strings.HasPrefix(txt, "v=DMARC1") {
-				fmt.Printf("DEBUG: Found DMARC Record: %s\n", txt)
-				riskFactors.LiveDMARCRecord = txt
+			policy, err := dmarc.ParsePolicy(txt)
+			if err != nil {
+				continue
+			}
+			fmt.Printf("DEBUG: Found DMARC Record: %s\n", txt)
+			riskFactors.LiveDMARCRecord = txt
+			riskFactors.DMARCPolicy = policy.Policy
+			riskFactors.DMARCPercent = policy.Percent
+			riskFactors.DMARCAlignmentStrict = policy.AlignmentStrict()
+			riskFactors.DMARCReportingURIs = policy.ReportURIs
+
+			// p=reject enforced with a passing DMARC check is a much stronger
+			// signal than p=none, which carries almost no enforcement value.
+			if dmarcPass {
+				switch policy.Policy {
+				case "reject":
+					breakdown.AuthPassBonus += 20
+				case "quarantine":
+					breakdown.AuthPassBonus += 10
+				default: // "none"
+					breakdown.AuthPassBonus += 1
+				}
 			}
 		}
 
+		if dkimPresent {
+			riskFactors.DKIMAligned = domainsAligned(fromDomain, dkimDomain, riskFactors.DMARCAlignmentStrict)
+		}
+
 		// Domain Trust Check
 		riskFactors.DomainTrustScore = checkDomainTrust(fromDomain)
+		riskFactors.DomainTrustScore = downgradeForAuthMismatch(riskFactors.DomainTrustScore, riskFactors.SPFStatus, riskFactors.DKIMStatus, riskFactors.DMARCStatus, dkimPresent, riskFactors.DKIMAligned)
 		fmt.Printf("DEBUG: Domain '%s' Trust Score: %s\n", fromDomain, riskFactors.DomainTrustScore)
 
-		// Public API Blacklist Check (spamhaus dbl via google dns)
-		riskFactors.BlacklistStatus = checkBlacklist(fromDomain)
+		// Multi-source DNSBL Blacklist Check (Spamhaus DBL, SURBL, URIBL, Barracuda, SpamCop)
+		riskFactors.BlacklistEvidence = checkBlacklistDetailed(fromDomain)
+		riskFactors.BlacklistStatus = riskFactors.BlacklistEvidence.Status
 		fmt.Printf("DEBUG: Domain '%s' Blacklist Status: %s\n", fromDomain, riskFactors.BlacklistStatus)
 
+		// RDAP registration signals: a domain impersonating a brand but
+		// registered days ago, often behind a privacy proxy, is a common
+		// phishing pattern the allowlist alone can't catch.
+		if info, err := rdapClient.Lookup(fromDomain); err == nil {
+			riskFactors.DomainRegistrar = info.Registrar
+			riskFactors.DomainAgeDays = info.AgeDays()
+			riskFactors.DomainPrivacyProxy = info.PrivacyProxy
+			if riskFactors.DomainAgeDays >= 0 && riskFactors.DomainAgeDays < 30 {
+				riskFactors.DomainIsNew = true
+				breakdown.BaseScore += 25
+			}
+			if info.PrivacyProxy {
+				breakdown.BaseScore += 10
+			}
+		} else {
+			fmt.Printf("DEBUG: RDAP lookup failed for %s: %v\n", fromDomain, err)
+		}
+
 	} else {
 		fmt.Println("DEBUG: No domain extracted, Trust Score: Unknown")
 		riskFactors.DomainTrustScore = "Unknown"
 		riskFactors.BlacklistStatus = "Unknown"
 	}
 
+	// --- 1b. Link and Attachment Analysis ---
+
+	links := extractLinks(body)
+	for _, link := range links {
+		if link.MismatchWithDisplay {
+			riskFactors.HasLinkMismatch = true
+			breakdown.LinkMismatchPenalty += 20
+		}
+		// High-risk individual links (IP-literal hosts, embedded userinfo,
+		// punycode, etc.) contribute even when the display text doesn't
+		// disagree with the href.
+		if link.RiskScore >= 40 {
+			breakdown.LinkMismatchPenalty += link.RiskScore / 4
+		}
+	}
+	// Cap so a single spammy newsletter with many links doesn't dominate the score.
+	if breakdown.LinkMismatchPenalty > 40 {
+		breakdown.LinkMismatchPenalty = 40
+	}
+
+	for _, att := range attachments {
+		if att.IsDangerous {
+			riskFactors.HasDangerousAttach = true
+			breakdown.AttachmentPenalty += 35
+		}
+		if att.DoubleExtension {
+			breakdown.AttachmentPenalty += 15
+		}
+	}
+
 	// --- 2. Linguistic Analysis (Body) ---
 
-	cleanBody := regexp.MustCompile(`<[^>]*>`).ReplaceAllString(body, " ")
+	cleanBody := htmltext.Extract(body)
 
 	// Language Detection
 	info := whatlanggo.Detect(cleanBody)
@@ -618,11 +818,25 @@ func analyzeEmail(filename string, msg *mail.Message, body string) AnalysisResul
 		}
 	}
 
+	// --- Bayes Classifier Score ---
+	bayesScore := 0.0
+	if bayesModel != nil {
+		probPhish, err := bayesModel.Classify(subject + " " + analysisText)
+		if err != nil {
+			fmt.Printf("DEBUG: Bayes classify skipped: %v\n", err)
+		} else {
+			bayesScore = probPhish * 100
+			breakdown.BayesPenalty = probPhish * 30
+		}
+	}
+
 	// --- Final Score Calculation ---
 
 	// Technical Score (max 40 points -> scale to 100%)
 	techRaw := breakdown.BaseScore + breakdown.AuthFailPenalty - breakdown.AuthPassBonus +
-		breakdown.MismatchPenalty + breakdown.NoMXPenalty + breakdown.DisposablePenalty
+		breakdown.MismatchPenalty + breakdown.NoMXPenalty + breakdown.DisposablePenalty +
+		breakdown.LinkMismatchPenalty + breakdown.AttachmentPenalty + breakdown.UpstreamPenalty +
+		breakdown.HomographPenalty
 	if techRaw < 0 {
 		techRaw = 0
 	}
@@ -645,8 +859,8 @@ func analyzeEmail(filename string, msg *mail.Message, body string) AnalysisResul
 	}
 	subjectScore := (subjectRaw / 30.0) * 100
 
-	// Weighted total: Tech 40%, Body 35%, Subject 25%
-	total := (techScore * 0.40) + (bodyScore * 0.35) + (subjectScore * 0.25)
+	// Weighted total: Tech 35%, Body 30%, Subject 20%, Bayes 15%
+	total := (techScore * 0.35) + (bodyScore * 0.30) + (subjectScore * 0.20) + (bayesScore * 0.15)
 	if total > 100 {
 		total = 100
 	}
@@ -682,6 +896,7 @@ func analyzeEmail(filename string, msg *mail.Message, body string) AnalysisResul
 		TechScore:       techScore,
 		BodyScore:       bodyScore,
 		SubjectScore:    subjectScore,
+		BayesScore:      bayesScore,
 		EmailBody:       highlightedBody,
 		HTMLBody:        template.HTML(safeHTML),
 		Headers: map[string]string{
@@ -694,6 +909,8 @@ func analyzeEmail(filename string, msg *mail.Message, body string) AnalysisResul
 		ScoreBreakdown:  breakdown,
 		BodyTriggers:    bodyTriggers,
 		SubjectTriggers: subjectTriggers,
+		Links:           links,
+		Attachments:     attachments,
 	}
 }
 
@@ -709,24 +926,47 @@ func sanitizeHTMLForPreview(input string) string {
 	return text
 }
 
+// stripMboxPrefix removes a leading "From " envelope line, the one piece of
+// mbox framing that can show up on a single message pulled out of a mailbox
+// (the full mbox splitter lives in batch.go's splitMbox).
+func stripMboxPrefix(content string) string {
+	if !strings.HasPrefix(content, "From ") {
+		return content
+	}
+	if idx := strings.Index(content, "\n"); idx != -1 {
+		return content[idx+1:]
+	}
+	return content
+}
+
 func extractEmailBody(msg *mail.Message) string {
+	body, _ := extractEmailBodyAndAttachments(msg)
+	return body
+}
+
+// extractEmailBodyAndAttachments is like extractEmailBody but also returns
+// every non-text MIME part found along the way.
+func extractEmailBodyAndAttachments(msg *mail.Message) (string, []AttachmentArtifact) {
+	var attachments []AttachmentArtifact
+
 	contentType := msg.Header.Get("Content-Type")
 	mediaType, params, err := mime.ParseMediaType(contentType)
 	if err != nil {
 		b, _ := io.ReadAll(msg.Body)
-		return string(b)
+		return string(b), attachments
 	}
 
 	if strings.HasPrefix(mediaType, "multipart/") {
-		return parseMultipart(msg.Body, params["boundary"])
+		body := parseMultipart(msg.Body, params["boundary"], &attachments)
+		return body, attachments
 	}
 
 	// Not multipart
 	b, _ := io.ReadAll(msg.Body)
-	return decodeContent(string(b), msg.Header.Get("Content-Transfer-Encoding"))
+	return decodeContent(string(b), msg.Header.Get("Content-Transfer-Encoding")), attachments
 }
 
-func parseMultipart(r io.Reader, boundary string) string {
+func parseMultipart(r io.Reader, boundary string, attachments *[]AttachmentArtifact) string {
 	mr := multipart.NewReader(r, boundary)
 	var htmlBody, textBody string
 
@@ -743,10 +983,13 @@ func parseMultipart(r io.Reader, boundary string) string {
 		contentType := p.Header.Get("Content-Type")
 		mediaType, params, _ := mime.ParseMediaType(contentType)
 		cte := p.Header.Get("Content-Transfer-Encoding")
+		contentDisposition := p.Header.Get("Content-Disposition")
+
+		filename, isAttachment := isAttachmentPart(contentDisposition, mediaType, params)
 
 		if strings.HasPrefix(mediaType, "multipart/") {
 			// RECURSIVE CALL
-			subContent := parseMultipart(p, params["boundary"])
+			subContent := parseMultipart(p, params["boundary"], attachments)
 			// Heuristic: If we found something in the sub-part, use it.
 			// Prefer HTML from sub-parts if it looks like HTML
 			if strings.Contains(strings.ToLower(subContent), "<html") ||
@@ -760,6 +1003,10 @@ func parseMultipart(r io.Reader, boundary string) string {
 					textBody = subContent
 				}
 			}
+		} else if isAttachment {
+			raw := readAllPart(p)
+			decoded := decodeContent(string(raw), cte)
+			*attachments = append(*attachments, classifyAttachment(filename, mediaType, []byte(decoded)))
 		} else if mediaType == "text/html" {
 			b, _ := io.ReadAll(p)
 			htmlBody = decodeContent(string(b), cte)
@@ -869,98 +1116,69 @@ func getDomain(email string) string {
 	return ""
 }
 
+// trustedBrandDomains is the allowlist checkDomainTrust matches against,
+// also used by homoglyph.Match to catch lookalikes of these same brands.
+var trustedBrandDomains = []string{
+	"google.com", "gmail.com",
+	"microsoft.com", "outlook.com", "hotmail.com",
+	"apple.com", "icloud.com",
+	"amazon.com",
+	"linkedin.com",
+	"paypal.com",
+	"slack.com",
+	"acquire.com",
+	"reddit.com", "redditmail.com",
+}
+
 func checkDomainTrust(domain string) string {
-	// Simple allowlist for demonstration
-	trusted := []string{
-		"google.com", "gmail.com",
-		"microsoft.com", "outlook.com", "hotmail.com",
-		"apple.com", "icloud.com",
-		"amazon.com",
-		"linkedin.com",
-		"paypal.com",
-		"slack.com",
-		"acquire.com",
-		"reddit.com", "redditmail.com",
-	}
-
-	for _, t := range trusted {
+	if domainBlocklist.Blocked(domain) {
+		return "Suspicious"
+	}
+
+	for _, t := range trustedBrandDomains {
 		if domain == t || strings.HasSuffix(domain, "."+t) {
 			return "Trustworthy"
 		}
 	}
+
+	// Punycode labels and confusable/typo-squatted skeletons are both
+	// checked by Match; a bare "xn--" label with no brand match still
+	// isn't enough signal on its own to flag, so it falls through to
+	// Neutral like any other unrecognized domain.
+	if matched, ok := homoglyph.Match(domain, trustedBrandDomains); ok {
+		return "Lookalike (" + matched + ")"
+	}
+
 	return "Neutral"
 }
 
-// Google DoH Response Structure (minimal)
-type DoHResponse struct {
-	Status int `json:"Status"` // 0 = NOERROR, 3 = NXDOMAIN
-	Answer []struct {
-		Name string `json:"name"`
-		Type int    `json:"type"`
-		Data string `json:"data"`
-	} `json:"Answer"`
-}
+// reputationAggregator is the shared, cached multi-source DNSBL checker that
+// replaced the single hardcoded Spamhaus lookup. Package-level like the rest
+// of the module's shared state (globalStats, bayesModel).
+var reputationAggregator = reputation.NewAggregator(reputation.DefaultProviders(), 10*time.Minute)
+
+// rdapClient fetches domain registration age/registrar, cached on disk for
+// a week since RDAP data barely changes once a domain is registered.
+var rdapClient = rdap.NewClient(resolvePath("data/rdap_cache"), 7*24*time.Hour)
 
+// checkBlacklist keeps the original single-string return value ("Clean" /
+// "Listed" / "Unknown") that callers and templates already expect, backed
+// now by the concurrent multi-provider aggregator.
 func checkBlacklist(domain string) string {
-	// 1. Try Google DoH API first (as requested)
-	apiURL := fmt.Sprintf("https://dns.google/resolve?name=%s.dbl.spamhaus.org&type=A", domain)
-	status := "Unknown"
-
-	client := http.Client{Timeout: 3 * time.Second}
-	resp, err := client.Get(apiURL)
-	if err == nil && resp.StatusCode == 200 {
-		var doh DoHResponse
-		if json.NewDecoder(resp.Body).Decode(&doh) == nil {
-			if doh.Status == 3 {
-				resp.Body.Close()
-				return "Clean" // Specified NXDOMAIN = Clean
-			}
-			if doh.Status == 0 && len(doh.Answer) > 0 {
-				ip := doh.Answer[0].Data
-				// Check for Open Resolver Block return code
-				if ip == "127.255.255.254" {
-					fmt.Println("DEBUG: Google DoH blocked by Spamhaus. Falling back to system DNS.")
-					status = "Fallback"
-				} else {
-					fmt.Printf("DEBUG: API found match: %s\n", ip)
-					resp.Body.Close()
-					return "Listed"
-				}
-			}
-		}
-		resp.Body.Close()
-	} else {
-		if err != nil {
-			fmt.Printf("DEBUG: DoH API Error: %v\n", err)
-		} else {
-			fmt.Printf("DEBUG: DoH API Status: %d\n", resp.StatusCode)
-			resp.Body.Close()
-		}
-		status = "Fallback"
+	if domainBlocklist.Blocked(domain) {
+		return "Listed"
 	}
+	result := checkBlacklistDetailed(domain)
+	return result.Status
+}
 
-	// 2. Fallback to System DNS (if API failed or was blocked)
-	// This ensures we actually get a result even if Public APIs are rate-limited
-	if status == "Fallback" {
-		lookupName := domain + ".dbl.spamhaus.org"
-		ips, err := net.LookupHost(lookupName)
-		if err != nil {
-			// lookup error usually means NXDOMAIN -> Clean
-			// But strictly check if it is a "no such host" error
-			if strings.Contains(err.Error(), "no such host") {
-				return "Clean"
-			}
-			// Other error
-			return "Error"
-		}
-		if len(ips) > 0 {
-			// Check again for block codes just in case local is also blocked
-			if ips[0] == "127.255.255.254" {
-				return "Error (Blocked)"
-			}
-			return "Listed"
-		}
+// checkBlacklistDetailed returns the full per-source evidence, for callers
+// (the analysis view) that want to show which zones matched.
+func checkBlacklistDetailed(domain string) reputation.Result {
+	if domainBlocklist.Blocked(domain) {
+		return reputation.Result{Domain: domain, Listed: true, Confidence: 1.0, Status: "Listed"}
 	}
-
-	return "Clean"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return reputationAggregator.Check(ctx, domain)
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jonasboos/phishing-live/classifier"
+)
+
+// runTrain implements the `train` subcommand: it walks the bundled phishing
+// corpus plus a user-supplied ham directory and updates the Bayes model
+// stored in data/classifier.db.
+func runTrain(hamDir string) {
+	if hamDir == "" {
+		fmt.Println("Usage: server train <ham-directory>")
+		os.Exit(1)
+	}
+
+	dbPath := resolvePath("data/classifier.db")
+	model, err := classifier.Open(dbPath)
+	if err != nil {
+		fmt.Printf("Error opening classifier store: %v\n", err)
+		os.Exit(1)
+	}
+	defer model.Close()
+
+	phishDir := resolvePath("data/test_emails")
+	phishCount := trainDir(model, phishDir, classifier.Phish)
+	hamCount := trainDir(model, hamDir, classifier.Ham)
+
+	fmt.Printf("Training complete. Phish emails: %d, Ham emails: %d\n", phishCount, hamCount)
+}
+
+func trainDir(model *classifier.Classifier, dir string, label classifier.Label) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		fmt.Printf("Warning: could not read %s directory %s: %v\n", label, dir, err)
+		return 0
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".eml") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Printf("Warning: skipping %s: %v\n", path, err)
+			continue
+		}
+
+		msg, err := mail.ReadMessage(f)
+		if err != nil {
+			f.Close()
+			fmt.Printf("Warning: could not parse %s: %v\n", path, err)
+			continue
+		}
+		body := extractEmailBody(msg)
+		subject := decodeHeader(msg.Header.Get("Subject"))
+		f.Close()
+
+		if err := model.Train(subject+" "+body, label); err != nil {
+			fmt.Printf("Warning: training failed for %s: %v\n", path, err)
+			continue
+		}
+		count++
+	}
+	return count
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jonasboos/phishing-live/dmarc"
+)
+
+// UpstreamVerdict captures a prior scanner's opinion of the message, carried
+// in headers that many mail systems stamp before delivery.
+type UpstreamVerdict struct {
+	Scanner   string   `json:"scanner"`
+	Score     float64  `json:"score"`
+	Threshold float64  `json:"threshold"`
+	Symbols   []string `json:"symbols,omitempty"`
+	Flagged   bool     `json:"flagged"`
+}
+
+var spamStatusScore = regexp.MustCompile(`(?i)score=(-?[0-9.]+)`)
+var spamStatusThreshold = regexp.MustCompile(`(?i)required=(-?[0-9.]+)`)
+var rspamdPhishSymbol = regexp.MustCompile(`(?i)^(PHISH|BAYES_99|FORGED_)`)
+
+// collectUpstreamVerdicts reads the headers several common scanners stamp
+// (SpamAssassin, Rspamd, Microsoft Defender, Proofpoint) and turns them into
+// a uniform list of verdicts plus a score penalty when any of them already
+// flagged the message.
+func collectUpstreamVerdicts(header mail.Header) ([]UpstreamVerdict, float64) {
+	var verdicts []UpstreamVerdict
+	var penalty float64
+
+	if v, ok := parseSpamAssassin(header); ok {
+		verdicts = append(verdicts, v)
+		if v.Flagged {
+			penalty += 25
+		}
+	}
+	if v, ok := parseRspamd(header); ok {
+		verdicts = append(verdicts, v)
+		if v.Flagged {
+			penalty += 25
+		}
+	}
+	if v, ok := parseMicrosoftAntispam(header); ok {
+		verdicts = append(verdicts, v)
+		if v.Flagged {
+			penalty += 20
+		}
+	}
+	if v, ok := parseProofpoint(header); ok {
+		verdicts = append(verdicts, v)
+		if v.Flagged {
+			penalty += 20
+		}
+	}
+
+	return verdicts, penalty
+}
+
+// parseSpamAssassin reads X-Spam-Score / X-Spam-Status / X-Spam-Level.
+func parseSpamAssassin(header mail.Header) (UpstreamVerdict, bool) {
+	status := header.Get("X-Spam-Status")
+	scoreHeader := header.Get("X-Spam-Score")
+	level := header.Get("X-Spam-Level")
+	if status == "" && scoreHeader == "" && level == "" {
+		return UpstreamVerdict{}, false
+	}
+
+	v := UpstreamVerdict{Scanner: "SpamAssassin"}
+	if m := spamStatusScore.FindStringSubmatch(status); m != nil {
+		v.Score, _ = strconv.ParseFloat(m[1], 64)
+	} else if scoreHeader != "" {
+		v.Score, _ = strconv.ParseFloat(strings.TrimSpace(scoreHeader), 64)
+	} else if level != "" {
+		// X-Spam-Level encodes the score as a run of '*', one per point.
+		v.Score = float64(strings.Count(level, "*"))
+	}
+	if m := spamStatusThreshold.FindStringSubmatch(status); m != nil {
+		v.Threshold, _ = strconv.ParseFloat(m[1], 64)
+	} else {
+		v.Threshold = 5.0 // SpamAssassin's common default
+	}
+	v.Flagged = strings.HasPrefix(strings.ToLower(status), "yes") || v.Score >= v.Threshold
+	return v, true
+}
+
+// parseRspamd reads X-Rspamd-Score / X-Rspamd-Symbols.
+func parseRspamd(header mail.Header) (UpstreamVerdict, bool) {
+	scoreHeader := header.Get("X-Rspamd-Score")
+	symbolsHeader := header.Get("X-Rspamd-Symbols")
+	if scoreHeader == "" && symbolsHeader == "" {
+		return UpstreamVerdict{}, false
+	}
+
+	v := UpstreamVerdict{Scanner: "Rspamd"}
+	v.Score, _ = strconv.ParseFloat(strings.TrimSpace(scoreHeader), 64)
+
+	for _, s := range strings.Split(symbolsHeader, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		v.Symbols = append(v.Symbols, s)
+		if rspamdPhishSymbol.MatchString(s) {
+			v.Flagged = true
+		}
+	}
+	return v, true
+}
+
+// parseMicrosoftAntispam reads the SCL (Spam Confidence Level) and BCL (Bulk
+// Confidence Level) out of X-Microsoft-Antispam. SCL >= 5 is Microsoft's own
+// "likely spam" cutoff.
+func parseMicrosoftAntispam(header mail.Header) (UpstreamVerdict, bool) {
+	raw := header.Get("X-Microsoft-Antispam")
+	if raw == "" {
+		return UpstreamVerdict{}, false
+	}
+
+	v := UpstreamVerdict{Scanner: "Microsoft Defender", Threshold: 5}
+	for _, field := range strings.Split(raw, ";") {
+		field = strings.TrimSpace(field)
+		if scl, ok := strings.CutPrefix(field, "SCL:"); ok {
+			v.Score, _ = strconv.ParseFloat(strings.TrimSpace(scl), 64)
+		}
+		if bcl, ok := strings.CutPrefix(field, "BCL:"); ok {
+			v.Symbols = append(v.Symbols, "BCL:"+strings.TrimSpace(bcl))
+		}
+	}
+	v.Flagged = v.Score >= v.Threshold
+	return v, true
+}
+
+// parseProofpoint reads X-Proofpoint-Spam-Details, which carries a rule list
+// rather than a numeric score.
+func parseProofpoint(header mail.Header) (UpstreamVerdict, bool) {
+	raw := header.Get("X-Proofpoint-Spam-Details")
+	if raw == "" {
+		return UpstreamVerdict{}, false
+	}
+
+	v := UpstreamVerdict{Scanner: "Proofpoint"}
+	lower := strings.ToLower(raw)
+	v.Flagged = strings.Contains(lower, "rule=") && (strings.Contains(lower, "spam") || strings.Contains(lower, "phish"))
+	return v, true
+}
+
+// parseForwardedAuth parses Received-SPF and ARC-Authentication-Results,
+// which the original analyzer ignored in favor of only the top-level
+// Authentication-Results header. This matters for forwarded mail carrying
+// an ARC chain.
+func parseForwardedAuth(header mail.Header) []dmarc.MethodResult {
+	var results []dmarc.MethodResult
+
+	if receivedSPF := header.Get("Received-SPF"); receivedSPF != "" {
+		result := strings.ToLower(strings.Fields(receivedSPF)[0])
+		results = append(results, dmarc.MethodResult{Mechanism: "spf", Result: result})
+	}
+
+	if arc := header.Get("ARC-Authentication-Results"); arc != "" {
+		results = append(results, dmarc.ParseAuthResults(arc)...)
+	}
+
+	return results
+}
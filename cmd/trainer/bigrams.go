@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+)
+
+// bigramBackoffAlpha is the stupid-backoff discount applied when a bigram
+// was never observed but its second word was.
+const bigramBackoffAlpha = 0.4
+
+// bigramTopN is how many discriminative bigrams make each of
+// TopScamBigrams/TopSafeBigrams.
+const bigramTopN = 25
+
+// bigramKey joins a bigram's two words into a single map key.
+func bigramKey(prev, cur string) string {
+	return prev + "\x01" + cur
+}
+
+// splitBigramKey reverses bigramKey.
+func splitBigramKey(key string) (prev, cur string) {
+	parts := strings.SplitN(key, "\x01", 2)
+	if len(parts) != 2 {
+		return key, ""
+	}
+	return parts[0], parts[1]
+}
+
+// BigramModel holds raw (non-deduplicated) unigram and bigram occurrence
+// counts for one class, letting ScoreBigram combine both kinds of evidence
+// via stupid backoff.
+type BigramModel struct {
+	BigramCounts  map[string]int `json:"bigram_counts"`
+	UnigramCounts map[string]int `json:"unigram_counts"`
+	UnigramTotal  int            `json:"unigram_total"`
+}
+
+func newBigramModel() *BigramModel {
+	return &BigramModel{
+		BigramCounts:  make(map[string]int),
+		UnigramCounts: make(map[string]int),
+	}
+}
+
+// addToken records one occurrence of cur following prev (prev == "" for the
+// first token in a document, which only updates the unigram count).
+func (m *BigramModel) addToken(prev, cur string) {
+	m.UnigramCounts[cur]++
+	m.UnigramTotal++
+	if prev != "" {
+		m.BigramCounts[bigramKey(prev, cur)]++
+	}
+}
+
+// merge folds other's counts into m, used to reduce per-worker shard
+// bigram models into one corpus-wide model.
+func (m *BigramModel) merge(other *BigramModel) {
+	if other == nil {
+		return
+	}
+	for k, c := range other.BigramCounts {
+		m.BigramCounts[k] += c
+	}
+	for w, c := range other.UnigramCounts {
+		m.UnigramCounts[w] += c
+	}
+	m.UnigramTotal += other.UnigramTotal
+}
+
+// ScoreBigram returns P(cur|prev) under stupid backoff: the observed
+// bigram's conditional probability when seen, else bigramBackoffAlpha times
+// cur's unigram probability, else (for a cur never seen at all) a
+// length-penalized floor so longer unseen words score lower than shorter
+// ones.
+func (m *BigramModel) ScoreBigram(prev, cur string) float64 {
+	if bigramCount, ok := m.BigramCounts[bigramKey(prev, cur)]; ok && bigramCount > 0 {
+		if prevCount := m.UnigramCounts[prev]; prevCount > 0 {
+			return float64(bigramCount) / float64(prevCount)
+		}
+	}
+
+	if curCount, ok := m.UnigramCounts[cur]; ok && curCount > 0 && m.UnigramTotal > 0 {
+		return bigramBackoffAlpha * float64(curCount) / float64(m.UnigramTotal)
+	}
+
+	n := m.UnigramTotal
+	if n == 0 {
+		n = 1
+	}
+	return 10 / (float64(n) * math.Pow(10, float64(len(cur))))
+}
+
+// BigramScore is one bigram's log-likelihood-ratio association with the
+// Scam or Safe class, mirroring IndicatorScore but over two-word phrases.
+type BigramScore struct {
+	Bigram    string  `json:"bigram"`
+	LLR       float64 `json:"llr"`
+	ScamCount int     `json:"scam_count"`
+	SafeCount int     `json:"safe_count"`
+}
+
+// BigramStats is the persisted form of both classes' bigram models, written
+// to bigram_stats.json so a downstream classifier can load ScoreBigram
+// without re-running the corpus analysis.
+type BigramStats struct {
+	Safe *BigramModel `json:"safe"`
+	Scam *BigramModel `json:"scam"`
+}
+
+// writeBigramModels persists safe/scam's bigram models to
+// ../../data/bigram_stats.json.
+func writeBigramModels(safe, scam *BigramModel) {
+	if err := writeJSON("../../data/bigram_stats.json", BigramStats{Safe: safe, Scam: scam}); err != nil {
+		log.Printf("Warning: could not write bigram_stats.json: %v", err)
+		return
+	}
+	fmt.Println("Done! Bigram model written to bigram_stats.json")
+}
+
+// topBigrams ranks every bigram seen in either class's BigramModel by
+// log-likelihood ratio, returning the topN most scam-leaning and
+// safe-leaning bigrams with at least minCount combined occurrences.
+func topBigrams(safeModel, scamModel *BigramModel, minCount, topN int) (topScam, topSafe []BigramScore) {
+	scamTotal := float64(scamModel.UnigramTotal)
+	safeTotal := float64(safeModel.UnigramTotal)
+
+	seen := make(map[string]bool, len(safeModel.BigramCounts)+len(scamModel.BigramCounts))
+	for k := range safeModel.BigramCounts {
+		seen[k] = true
+	}
+	for k := range scamModel.BigramCounts {
+		seen[k] = true
+	}
+
+	var scores []BigramScore
+	for k := range seen {
+		safe := safeModel.BigramCounts[k]
+		scam := scamModel.BigramCounts[k]
+		if safe+scam < minCount {
+			continue
+		}
+		prev, cur := splitBigramKey(k)
+		scores = append(scores, BigramScore{
+			Bigram:    prev + " " + cur,
+			LLR:       logLikelihoodRatio(scam, safe, scamTotal, safeTotal),
+			ScamCount: scam,
+			SafeCount: safe,
+		})
+	}
+
+	scamSorted := append([]BigramScore(nil), scores...)
+	sort.Slice(scamSorted, func(i, j int) bool { return scamSorted[i].LLR > scamSorted[j].LLR })
+	for _, s := range scamSorted {
+		if s.LLR <= 0 {
+			break
+		}
+		topScam = append(topScam, s)
+		if len(topScam) == topN {
+			break
+		}
+	}
+
+	safeSorted := append([]BigramScore(nil), scores...)
+	sort.Slice(safeSorted, func(i, j int) bool { return safeSorted[i].LLR < safeSorted[j].LLR })
+	for _, s := range safeSorted {
+		if s.LLR >= 0 {
+			break
+		}
+		topSafe = append(topSafe, s)
+		if len(topSafe) == topN {
+			break
+		}
+	}
+
+	return topScam, topSafe
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+)
+
+// These constants configure CharNGramModel, a logistic regression classifier
+// over hashed character 3-5 grams - a second opinion alongside the word-level Naive Bayes model,
+// robust to token-level obfuscation ("cl1ck h3re") that wordRegex strips.
+const (
+	hashBuckets  = 1 << 18
+	minGramLen   = 3
+	maxGramLen   = 5
+	learningRate = 0.05
+	l2Lambda     = 0.0001
+	epochs       = 5
+	batchSize    = 64
+)
+
+// CharNGramModel holds the trained weights, gob-encoded to clf.gob.
+type CharNGramModel struct {
+	Weights []float64
+	Bias    float64
+}
+
+var loadedCharNGramModel *CharNGramModel
+
+// hashFeatures extracts character 3-5 grams from text and accumulates them
+// into a fixed-width hashed feature vector (the hashing trick), so the
+// vocabulary size never depends on the corpus.
+func hashFeatures(text string) map[int]float64 {
+	lower := strings.ToLower(text)
+	runes := []rune(lower)
+	features := make(map[int]float64)
+
+	for n := minGramLen; n <= maxGramLen; n++ {
+		for i := 0; i+n <= len(runes); i++ {
+			gram := string(runes[i : i+n])
+			bucket := int(fnv32a(gram)) % hashBuckets
+			if bucket < 0 {
+				bucket += hashBuckets
+			}
+			features[bucket]++
+		}
+	}
+	return features
+}
+
+// fnv32a is a tiny inline FNV-1a hash, avoiding a dependency on hash/fnv
+// just to hash short n-gram strings.
+func fnv32a(s string) uint32 {
+	const offset32 = 2166136261
+	const prime32 = 16777619
+	h := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= prime32
+	}
+	return h
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+// TrainCharNGram trains the char-n-gram logistic regression classifier over
+// the JSONEntry corpus at path via mini-batch SGD with L2 regularization,
+// and persists the resulting weights to ../../data/clf.gob.
+func TrainCharNGram(path string) error {
+	entries, err := loadJSONEntries(path)
+	if err != nil {
+		return fmt.Errorf("loading corpus: %w", err)
+	}
+	fmt.Printf("Loaded %d labeled documents for char-n-gram training\n", len(entries))
+
+	model := &CharNGramModel{Weights: make([]float64, hashBuckets)}
+
+	for epoch := 0; epoch < epochs; epoch++ {
+		rand.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+
+		for start := 0; start < len(entries); start += batchSize {
+			end := start + batchSize
+			if end > len(entries) {
+				end = len(entries)
+			}
+			trainBatch(model, entries[start:end])
+		}
+		fmt.Printf("Epoch %d/%d complete\n", epoch+1, epochs)
+	}
+
+	outPath := "../../data/clf.gob"
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", outPath, err)
+	}
+	defer f.Close()
+
+	if err := gob.NewEncoder(f).Encode(model); err != nil {
+		return fmt.Errorf("encoding model: %w", err)
+	}
+	fmt.Printf("Done! Char-n-gram model written to %s\n", outPath)
+	return nil
+}
+
+func trainBatch(model *CharNGramModel, batch []JSONEntry) {
+	gradients := make(map[int]float64)
+	var biasGrad float64
+
+	for _, entry := range batch {
+		features := hashFeatures(entry.Text)
+		label := float64(entry.Label) // 1 = Scam, 0 = Safe
+
+		var z float64
+		for idx, val := range features {
+			z += model.Weights[idx] * val
+		}
+		z += model.Bias
+
+		pred := sigmoid(z)
+		errTerm := pred - label
+
+		for idx, val := range features {
+			gradients[idx] += errTerm * val
+		}
+		biasGrad += errTerm
+	}
+
+	n := float64(len(batch))
+	for idx, grad := range gradients {
+		// L2 regularization shrinks weights alongside the gradient step.
+		model.Weights[idx] -= learningRate * (grad/n + l2Lambda*model.Weights[idx])
+	}
+	model.Bias -= learningRate * (biasGrad / n)
+}
+
+func loadJSONEntries(path string) ([]JSONEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	if _, err := dec.Token(); err != nil { // opening bracket
+		return nil, err
+	}
+
+	var entries []JSONEntry
+	for dec.More() {
+		var entry JSONEntry
+		if err := dec.Decode(&entry); err != nil {
+			log.Printf("Error decoding entry: %v", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// PredictCharNGram scores text with the trained char-n-gram model, lazily
+// loading clf.gob on first use. Returns the probability the text is scam
+// (1.0 = scam); returns -1 if no trained model is available.
+func PredictCharNGram(text string) float64 {
+	if loadedCharNGramModel == nil {
+		model, err := loadCharNGramModel("../../data/clf.gob")
+		if err != nil {
+			log.Printf("Char-n-gram model not available: %v", err)
+			return -1
+		}
+		loadedCharNGramModel = model
+	}
+
+	features := hashFeatures(text)
+	var z float64
+	for idx, val := range features {
+		z += loadedCharNGramModel.Weights[idx] * val
+	}
+	z += loadedCharNGramModel.Bias
+	return sigmoid(z)
+}
+
+func loadCharNGramModel(path string) (*CharNGramModel, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var model CharNGramModel
+	if err := gob.NewDecoder(f).Decode(&model); err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// topContributorCount is how many of a message's most "interesting" tokens
+// (Graham-style, by |p - 0.5|) drive the verdict, keeping noise words from
+// diluting the score.
+const topContributorCount = 15
+
+// Contributor is a single token's influence on a classify verdict.
+type Contributor struct {
+	Word   string  `json:"word"`
+	PScam  float64 `json:"p_scam"`
+	Weight float64 `json:"weight"` // |p_scam - 0.5| times the token's subject/body text weight, how much this word moved the verdict
+}
+
+// Verdict is the JSON output of the classify subcommand.
+type Verdict struct {
+	Score             float64       `json:"score"` // log-odds score; positive leans scam
+	ProbScam          float64       `json:"prob_scam"`
+	TopContributors   []Contributor `json:"top_contributors"`
+	CharNGramProbScam float64       `json:"char_ngram_prob_scam,omitempty"` // second opinion from PredictCharNGram; omitted (0) if clf.gob isn't trained
+}
+
+// ClassifyOptions configures the Laplace smoothing constant (alpha), a
+// minimum document-frequency cutoff below which a vocabulary word is
+// treated as unseen, and how much extra weight subject tokens carry
+// relative to body tokens when both are classified together.
+type ClassifyOptions struct {
+	Alpha         float64
+	MinCount      int
+	SubjectWeight float64
+}
+
+// DefaultClassifyOptions mirrors the classifier's original behavior: alpha=1
+// (add-one smoothing), no vocabulary cutoff, subject tokens weighted double.
+func DefaultClassifyOptions() ClassifyOptions {
+	return ClassifyOptions{Alpha: 1.0, MinCount: 1, SubjectWeight: 2.0}
+}
+
+// runClassify loads a word_stats.json model and scores the email at
+// inputPath (optionally combined with a separate subjectPath) as ham or
+// scam, writing the verdict as JSON to stdout.
+func runClassify(modelPath, inputPath, subjectPath string, opts ClassifyOptions) {
+	model, err := loadWordStats(modelPath)
+	if err != nil {
+		log.Fatalf("Unable to load model %s: %v", modelPath, err)
+	}
+
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		log.Fatalf("Unable to read %s: %v", inputPath, err)
+	}
+
+	var subjectText string
+	if subjectPath != "" {
+		subjectContent, err := os.ReadFile(subjectPath)
+		if err != nil {
+			log.Fatalf("Unable to read %s: %v", subjectPath, err)
+		}
+		subjectText = string(subjectContent)
+	}
+
+	verdict := classify(model, string(content), subjectText, opts)
+	if charScore := PredictCharNGram(string(content)); charScore >= 0 {
+		verdict.CharNGramProbScam = charScore
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(verdict); err != nil {
+		log.Fatalf("Unable to encode verdict: %v", err)
+	}
+}
+
+func loadWordStats(path string) (TrainingOutput, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return TrainingOutput{}, err
+	}
+	defer f.Close()
+
+	var model TrainingOutput
+	if err := json.NewDecoder(f).Decode(&model); err != nil {
+		return TrainingOutput{}, err
+	}
+	return model, nil
+}
+
+// tokenize applies the preprocessing used to build word_stats.json:
+// lowercase, wordRegex, length/numeric and stopWords removal, and dictionary
+// filtering when the dictionary is loaded. It also drops isIrrelevant words,
+// which analyzeJSON keeps in word_stats.json.
+func tokenize(text string) []string {
+	lower := strings.ToLower(text)
+	var tokens []string
+	for _, w := range wordRegex.FindAllString(lower, -1) {
+		if len(w) < 3 || isNumeric(w) {
+			continue
+		}
+		if stopWords[w] || isIrrelevant(w) {
+			continue
+		}
+		if len(validEnglishWords) > 0 && !validEnglishWords[w] {
+			continue
+		}
+		tokens = append(tokens, w)
+	}
+	return tokens
+}
+
+// effectiveVocabSize counts model words meeting opts.MinCount, so rare
+// words below the cutoff don't inflate V in the Laplace denominator.
+func effectiveVocabSize(model TrainingOutput, minCount int) int {
+	if minCount <= 1 {
+		return len(model.WordStats)
+	}
+	n := 0
+	for _, stats := range model.WordStats {
+		if stats.SafeCount+stats.ScamCount >= minCount {
+			n++
+		}
+	}
+	return n
+}
+
+// classify scores bodyText (optionally combined with subjectText, weighted
+// by opts.SubjectWeight) via multinomial Naive Bayes with Laplace smoothing
+// over model's per-word Safe/Scam document counts, restricting the sum to
+// the topContributorCount most informative tokens.
+func classify(model TrainingOutput, bodyText, subjectText string, opts ClassifyOptions) Verdict {
+	vocabSize := effectiveVocabSize(model, opts.MinCount)
+	totalSafe := float64(model.TotalSafeEmails)
+	totalScam := float64(model.TotalScamEmails)
+
+	type scored struct {
+		word           string
+		pScam          float64 // normalized P(scam|word), used only for ranking/display
+		weight         float64
+		textWeight     float64
+		rawPScamGivenW float64 // Laplace-smoothed P(word|scam), used for scoring
+		rawPSafeGivenW float64 // Laplace-smoothed P(word|safe), used for scoring
+	}
+
+	tokenWeights := make(map[string]float64)
+	// Subsampling (chunk3-5) runs on the tokenized stream before this
+	// dedup step, matching the training-time invariant so a padded email
+	// can't be classified differently than an equally-padded training
+	// document would have been counted.
+	for _, w := range subsampleTokens(tokenize(bodyText), subsampleThreshold, classifySampleRNG()) {
+		if tokenWeights[w] < 1.0 {
+			tokenWeights[w] = 1.0
+		}
+	}
+	if subjectText != "" {
+		for _, w := range subsampleTokens(tokenize(subjectText), subsampleThreshold, classifySampleRNG()) {
+			if tokenWeights[w] < opts.SubjectWeight {
+				tokenWeights[w] = opts.SubjectWeight
+			}
+		}
+	}
+
+	var candidates []scored
+	for w, textWeight := range tokenWeights {
+		stats, ok := model.WordStats[w]
+		safeCount, scamCount := 0, 0
+		if ok && stats.SafeCount+stats.ScamCount >= opts.MinCount {
+			safeCount, scamCount = stats.SafeCount, stats.ScamCount
+		}
+
+		pScamGivenWord := (float64(scamCount) + opts.Alpha) / (totalScam + opts.Alpha*float64(vocabSize))
+		pSafeGivenWord := (float64(safeCount) + opts.Alpha) / (totalSafe + opts.Alpha*float64(vocabSize))
+		p := pScamGivenWord / (pScamGivenWord + pSafeGivenWord)
+
+		candidates = append(candidates, scored{
+			word:           w,
+			pScam:          p,
+			weight:         math.Abs(p-0.5) * textWeight,
+			textWeight:     textWeight,
+			rawPScamGivenW: pScamGivenWord,
+			rawPSafeGivenW: pSafeGivenWord,
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].weight > candidates[j].weight
+	})
+	if len(candidates) > topContributorCount {
+		candidates = candidates[:topContributorCount]
+	}
+
+	var logScamSum, logSafeSum float64
+	if totalScam+totalSafe > 0 {
+		logScamSum = math.Log(totalScam / (totalScam + totalSafe))
+		logSafeSum = math.Log(totalSafe / (totalScam + totalSafe))
+	}
+
+	contributors := make([]Contributor, 0, len(candidates))
+	for _, c := range candidates {
+		// Each token's Laplace-smoothed log-likelihood is scaled by its
+		// textWeight, so a subject-only word pulls the verdict harder than a
+		// body word. c.pScam (the normalized P(scam|word) used to pick the
+		// top contributors above) is reported for display only - scoring
+		// sums the raw per-class likelihoods directly.
+		logScamSum += c.textWeight * math.Log(c.rawPScamGivenW)
+		logSafeSum += c.textWeight * math.Log(c.rawPSafeGivenW)
+		contributors = append(contributors, Contributor{Word: c.word, PScam: c.pScam, Weight: c.weight})
+	}
+
+	// Convert the two log-likelihoods back to a normalized probability via
+	// the log-sum-exp trick, avoiding overflow from exponentiating directly.
+	maxLog := math.Max(logScamSum, logSafeSum)
+	scamExp := math.Exp(logScamSum - maxLog)
+	safeExp := math.Exp(logSafeSum - maxLog)
+	probScam := scamExp / (scamExp + safeExp)
+
+	return Verdict{
+		Score:           logScamSum - logSafeSum,
+		ProbScam:        probScam,
+		TopContributors: contributors,
+	}
+}
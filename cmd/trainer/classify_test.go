@@ -0,0 +1,78 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// syntheticModel returns a small, hand-built word_stats.json-equivalent
+// model: "winner"/"urgent"/"lottery" are scam-only, "schedule"/"quarterly"
+// are safe-only, over unequal class sizes so the Laplace-smoothed
+// per-class likelihoods aren't numerically interchangeable with their
+// normalized ratio.
+func syntheticModel() TrainingOutput {
+	return TrainingOutput{
+		TotalSafeEmails: 5,
+		TotalScamEmails: 10,
+		WordStats: map[string]WordStats{
+			"winner":    {SafeCount: 0, ScamCount: 9},
+			"urgent":    {SafeCount: 0, ScamCount: 9},
+			"lottery":   {SafeCount: 0, ScamCount: 9},
+			"schedule":  {SafeCount: 4, ScamCount: 0},
+			"quarterly": {SafeCount: 4, ScamCount: 0},
+		},
+	}
+}
+
+func TestClassifyScamCorpus(t *testing.T) {
+	model := syntheticModel()
+	opts := ClassifyOptions{Alpha: 1.0, MinCount: 1, SubjectWeight: 2.0}
+
+	verdict := classify(model, "Congratulations winner you have won the lottery urgent action required", "", opts)
+
+	if verdict.Score <= 0 {
+		t.Errorf("Score = %v, want > 0 for an obviously scam-like body", verdict.Score)
+	}
+	if verdict.ProbScam <= 0.5 {
+		t.Errorf("ProbScam = %v, want > 0.5 for an obviously scam-like body", verdict.ProbScam)
+	}
+}
+
+func TestClassifySafeCorpus(t *testing.T) {
+	model := syntheticModel()
+	opts := ClassifyOptions{Alpha: 1.0, MinCount: 1, SubjectWeight: 2.0}
+
+	verdict := classify(model, "Let's schedule the meeting for our quarterly review", "", opts)
+
+	if verdict.Score >= 0 {
+		t.Errorf("Score = %v, want < 0 for an obviously safe body", verdict.Score)
+	}
+	if verdict.ProbScam >= 0.5 {
+		t.Errorf("ProbScam = %v, want < 0.5 for an obviously safe body", verdict.ProbScam)
+	}
+}
+
+// TestClassifyScoresRawLikelihoods pins the fix directly: Score must equal
+// the class-prior log-odds plus the sum of each contributing word's raw
+// Laplace-smoothed log P(word|scam)/log P(word|safe), not a log-odds
+// derived from the normalized P(scam|word) ranking metric.
+func TestClassifyScoresRawLikelihoods(t *testing.T) {
+	model := TrainingOutput{
+		TotalSafeEmails: 5,
+		TotalScamEmails: 10,
+		WordStats: map[string]WordStats{
+			"winner": {SafeCount: 0, ScamCount: 9},
+		},
+	}
+	opts := ClassifyOptions{Alpha: 1.0, MinCount: 1, SubjectWeight: 2.0}
+
+	verdict := classify(model, "winner", "", opts)
+
+	pScamGivenWord := (9.0 + opts.Alpha) / (10.0 + opts.Alpha*1)
+	pSafeGivenWord := (0.0 + opts.Alpha) / (5.0 + opts.Alpha*1)
+	wantScore := math.Log(10.0/15.0) - math.Log(5.0/15.0) + math.Log(pScamGivenWord) - math.Log(pSafeGivenWord)
+
+	if math.Abs(verdict.Score-wantScore) > 1e-9 {
+		t.Errorf("Score = %v, want %v (raw-likelihood log-odds)", verdict.Score, wantScore)
+	}
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonasboos/phishing-live/cardinality"
+)
+
+// jsonShard is one worker's local accumulator for analyzeJSON, merged into
+// a single result after every worker has drained its share of the corpus.
+// Keeping these per-worker avoids lock contention on the hot per-document
+// word-counting path.
+type jsonShard struct {
+	safeWordCounts  map[string]int
+	scamWordCounts  map[string]int
+	safeVocabSketch *cardinality.Sketch
+	scamVocabSketch *cardinality.Sketch
+	safeBigrams     *BigramModel
+	scamBigrams     *BigramModel
+
+	// safeCMS/scamCMS and safeHH/scamHH replace safeWordCounts/scamWordCounts
+	// in -sketch mode; both are nil otherwise.
+	safeCMS *CountMinSketch
+	scamCMS *CountMinSketch
+	safeHH  *HeavyHitters
+	scamHH  *HeavyHitters
+
+	// rng drives subsampleTokens' padding-attack defense; each shard gets
+	// its own source so concurrent workers never share (and contend on)
+	// one math/rand.Rand.
+	rng *rand.Rand
+
+	safeWordSum, scamWordSum   int
+	safeSentSum, scamSentSum   int
+	safeShoutSum, scamShoutSum float64
+	safeCount, scamCount       int
+}
+
+func newJSONShard(shardIndex int) *jsonShard {
+	shard := &jsonShard{
+		safeWordCounts:  make(map[string]int),
+		scamWordCounts:  make(map[string]int),
+		safeVocabSketch: cardinality.New(),
+		scamVocabSketch: cardinality.New(),
+		safeBigrams:     newBigramModel(),
+		scamBigrams:     newBigramModel(),
+		rng:             newSampleRNG(shardIndex),
+	}
+	if sketchMode {
+		shard.safeCMS = NewCountMinSketch(sketchWidth, sketchDepth)
+		shard.scamCMS = NewCountMinSketch(sketchWidth, sketchDepth)
+		shard.safeHH = NewHeavyHitters(sketchCapacity)
+		shard.scamHH = NewHeavyHitters(sketchCapacity)
+	}
+	return shard
+}
+
+// processJSONEntry runs the same preprocessing/linguistic-feature logic
+// analyzeJSON used to run inline, updating shard instead of shared state.
+func processJSONEntry(entry JSONEntry, shard *jsonShard, htmlTagRegex, sentSplit *regexp.Regexp) {
+	cleanText := htmlTagRegex.ReplaceAllString(entry.Text, " ")
+	textLower := strings.ToLower(cleanText)
+
+	words := wordRegex.FindAllString(textLower, -1)
+	uniqueWordsInDoc := make(map[string]bool)
+
+	bigramModel := shard.safeBigrams
+	if entry.Label == 1 {
+		bigramModel = shard.scamBigrams
+	}
+
+	var filtered []string
+	prev := ""
+	for _, w := range words {
+		if len(w) < 3 || isNumeric(w) {
+			continue
+		}
+		if len(validEnglishWords) > 0 && !validEnglishWords[w] {
+			continue
+		}
+		if stopWords[w] {
+			continue
+		}
+		filtered = append(filtered, w)
+
+		bigramModel.addToken(prev, w)
+		prev = w
+	}
+
+	// Resist padding attacks: cap how many tokens feed the per-document
+	// unique-word set, per the invariant documented on subsampleTokens.
+	// currWordCount is derived from filtered (pre-subsample), as
+	// processCSVRow's word count is also taken before subsampling (from its
+	// unfiltered bodyWords), so capping the unique-word set doesn't also
+	// silently cap AvgWordCount/AvgSentenceLength.
+	sampled := subsampleTokens(filtered, subsampleThreshold, shard.rng)
+	currWordCount := len(filtered)
+	for _, w := range sampled {
+		uniqueWordsInDoc[w] = true
+	}
+
+	for w := range uniqueWordsInDoc {
+		if entry.Label == 1 {
+			shard.scamVocabSketch.Add(w)
+			if sketchMode {
+				shard.scamCMS.Add(w, 1)
+				shard.scamHH.Add(w)
+			} else {
+				shard.scamWordCounts[w]++
+			}
+		} else {
+			shard.safeVocabSketch.Add(w)
+			if sketchMode {
+				shard.safeCMS.Add(w, 1)
+				shard.safeHH.Add(w)
+			} else {
+				shard.safeWordCounts[w]++
+			}
+		}
+	}
+
+	sentences := sentSplit.Split(cleanText, -1)
+	currSentCount := 0
+	for _, s := range sentences {
+		if len(strings.TrimSpace(s)) > 10 {
+			currSentCount++
+		}
+	}
+	if currSentCount == 0 {
+		currSentCount = 1
+	}
+
+	shoutScore := calculateShoutingScore(cleanText)
+
+	if entry.Label == 1 {
+		shard.scamCount++
+		shard.scamWordSum += currWordCount
+		shard.scamSentSum += currWordCount / currSentCount
+		shard.scamShoutSum += shoutScore
+	} else {
+		shard.safeCount++
+		shard.safeWordSum += currWordCount
+		shard.safeSentSum += currWordCount / currSentCount
+		shard.safeShoutSum += shoutScore
+	}
+}
+
+// mergeJSONShards reduces every worker's local jsonShard into a single
+// combined shard, summing counts and folding vocabulary sketches together.
+func mergeJSONShards(shards []*jsonShard) *jsonShard {
+	merged := newJSONShard(0)
+	for _, s := range shards {
+		if sketchMode {
+			merged.safeCMS.Merge(s.safeCMS)
+			merged.scamCMS.Merge(s.scamCMS)
+			merged.safeHH.Merge(s.safeHH)
+			merged.scamHH.Merge(s.scamHH)
+		} else {
+			for w, c := range s.safeWordCounts {
+				merged.safeWordCounts[w] += c
+			}
+			for w, c := range s.scamWordCounts {
+				merged.scamWordCounts[w] += c
+			}
+		}
+		merged.safeVocabSketch.Merge(s.safeVocabSketch)
+		merged.scamVocabSketch.Merge(s.scamVocabSketch)
+		merged.safeBigrams.merge(s.safeBigrams)
+		merged.scamBigrams.merge(s.scamBigrams)
+		merged.safeWordSum += s.safeWordSum
+		merged.scamWordSum += s.scamWordSum
+		merged.safeSentSum += s.safeSentSum
+		merged.scamSentSum += s.scamSentSum
+		merged.safeShoutSum += s.safeShoutSum
+		merged.scamShoutSum += s.scamShoutSum
+		merged.safeCount += s.safeCount
+		merged.scamCount += s.scamCount
+	}
+	return merged
+}
+
+// runJSONWorkers fans entries out across numWorkers goroutines, each
+// processing into its own jsonShard via processJSONEntry, and returns the
+// merged result once every entry has been consumed.
+func runJSONWorkers(entries []JSONEntry, numWorkers int, htmlTagRegex, sentSplit *regexp.Regexp) *jsonShard {
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+
+	jobs := make(chan JSONEntry, numWorkers*4)
+	shards := make([]*jsonShard, numWorkers)
+	var wg sync.WaitGroup
+
+	for i := 0; i < numWorkers; i++ {
+		shards[i] = newJSONShard(i)
+		wg.Add(1)
+		go func(shard *jsonShard) {
+			defer wg.Done()
+			for entry := range jobs {
+				processJSONEntry(entry, shard, htmlTagRegex, sentSplit)
+			}
+		}(shards[i])
+	}
+
+	for _, entry := range entries {
+		jobs <- entry
+	}
+	close(jobs)
+	wg.Wait()
+
+	return mergeJSONShards(shards)
+}
+
+// csvShard is one worker's local accumulator for analyzeCSV, mirroring
+// jsonShard but tracking body/subject word frequencies separately.
+type csvShard struct {
+	safeBodyWordCounts, scamBodyWordCounts       map[string]int
+	safeSubjectWordCounts, scamSubjectWordCounts map[string]int
+	safeBodyBigrams, scamBodyBigrams             *BigramModel
+
+	rng *rand.Rand
+
+	safeWordSum, scamWordSum   int
+	safeSentSum, scamSentSum   int
+	safeShoutSum, scamShoutSum float64
+	safeCount, scamCount       int
+}
+
+func newCSVShard(shardIndex int) *csvShard {
+	return &csvShard{
+		safeBodyWordCounts:    make(map[string]int),
+		scamBodyWordCounts:    make(map[string]int),
+		safeSubjectWordCounts: make(map[string]int),
+		scamSubjectWordCounts: make(map[string]int),
+		safeBodyBigrams:       newBigramModel(),
+		scamBodyBigrams:       newBigramModel(),
+		rng:                   newSampleRNG(shardIndex),
+	}
+}
+
+// csvRow is the subset of a parsed CSV record a worker needs, extracted by
+// the single reader goroutine since csv.Reader itself isn't safe for
+// concurrent use.
+type csvRow struct {
+	subject string
+	body    string
+	isScam  bool
+}
+
+// processCSVRow runs the same body/subject analysis analyzeCSV used to run
+// inline, updating shard instead of shared state.
+func processCSVRow(row csvRow, shard *csvShard, htmlTagRegex, sentSplit *regexp.Regexp) {
+	cleanBody := htmlTagRegex.ReplaceAllString(row.body, " ")
+	bodyLower := strings.ToLower(cleanBody)
+	bodyWords := wordRegex.FindAllString(bodyLower, -1)
+	uniqueBodyWords := make(map[string]bool)
+
+	bigramModel := shard.safeBodyBigrams
+	if row.isScam {
+		bigramModel = shard.scamBodyBigrams
+	}
+
+	var filteredBodyWords []string
+	prev := ""
+	for _, w := range bodyWords {
+		if len(w) < 3 || isNumeric(w) {
+			continue
+		}
+		if len(validEnglishWords) > 0 && !validEnglishWords[w] {
+			continue
+		}
+		if stopWords[w] {
+			continue
+		}
+		if isIrrelevant(w) {
+			continue
+		}
+		filteredBodyWords = append(filteredBodyWords, w)
+
+		bigramModel.addToken(prev, w)
+		prev = w
+	}
+
+	// Resist padding attacks: cap how many tokens feed uniqueBodyWords, per
+	// the invariant documented on subsampleTokens.
+	for _, w := range subsampleTokens(filteredBodyWords, subsampleThreshold, shard.rng) {
+		uniqueBodyWords[w] = true
+	}
+
+	subjLower := strings.ToLower(row.subject)
+	subjWords := wordRegex.FindAllString(subjLower, -1)
+	uniqueSubjWords := make(map[string]bool)
+	for _, w := range subjWords {
+		if len(w) < 2 || isNumeric(w) {
+			continue
+		}
+		if stopWords[w] {
+			continue
+		}
+		if isIrrelevant(w) {
+			continue
+		}
+		uniqueSubjWords[w] = true
+	}
+
+	sentences := sentSplit.Split(cleanBody, -1)
+	currSentCount := 0
+	for _, s := range sentences {
+		if len(strings.TrimSpace(s)) > 10 {
+			currSentCount++
+		}
+	}
+	if currSentCount == 0 {
+		currSentCount = 1
+	}
+
+	if row.isScam {
+		shard.scamCount++
+		shard.scamWordSum += len(bodyWords)
+		shard.scamShoutSum += calculateShoutingScore(cleanBody)
+		shard.scamSentSum += len(bodyWords) / currSentCount
+		for w := range uniqueBodyWords {
+			shard.scamBodyWordCounts[w]++
+		}
+		for w := range uniqueSubjWords {
+			shard.scamSubjectWordCounts[w]++
+		}
+	} else {
+		shard.safeCount++
+		shard.safeWordSum += len(bodyWords)
+		shard.safeShoutSum += calculateShoutingScore(cleanBody)
+		shard.safeSentSum += len(bodyWords) / currSentCount
+		for w := range uniqueBodyWords {
+			shard.safeBodyWordCounts[w]++
+		}
+		for w := range uniqueSubjWords {
+			shard.safeSubjectWordCounts[w]++
+		}
+	}
+}
+
+// mergeCSVShards reduces every worker's local csvShard into a single
+// combined shard.
+func mergeCSVShards(shards []*csvShard) *csvShard {
+	merged := newCSVShard(0)
+	for _, s := range shards {
+		for w, c := range s.safeBodyWordCounts {
+			merged.safeBodyWordCounts[w] += c
+		}
+		for w, c := range s.scamBodyWordCounts {
+			merged.scamBodyWordCounts[w] += c
+		}
+		for w, c := range s.safeSubjectWordCounts {
+			merged.safeSubjectWordCounts[w] += c
+		}
+		for w, c := range s.scamSubjectWordCounts {
+			merged.scamSubjectWordCounts[w] += c
+		}
+		merged.safeBodyBigrams.merge(s.safeBodyBigrams)
+		merged.scamBodyBigrams.merge(s.scamBodyBigrams)
+		merged.safeWordSum += s.safeWordSum
+		merged.scamWordSum += s.scamWordSum
+		merged.safeSentSum += s.safeSentSum
+		merged.scamSentSum += s.scamSentSum
+		merged.safeShoutSum += s.safeShoutSum
+		merged.scamShoutSum += s.scamShoutSum
+		merged.safeCount += s.safeCount
+		merged.scamCount += s.scamCount
+	}
+	return merged
+}
+
+// runCSVWorkers fans rows out across numWorkers goroutines, each processing
+// into its own csvShard via processCSVRow, and returns the merged result.
+func runCSVWorkers(rows <-chan csvRow, numWorkers int, htmlTagRegex, sentSplit *regexp.Regexp) *csvShard {
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+
+	shards := make([]*csvShard, numWorkers)
+	var wg sync.WaitGroup
+
+	for i := 0; i < numWorkers; i++ {
+		shards[i] = newCSVShard(i)
+		wg.Add(1)
+		go func(shard *csvShard) {
+			defer wg.Done()
+			for row := range rows {
+				processCSVRow(row, shard, htmlTagRegex, sentSplit)
+			}
+		}(shards[i])
+	}
+
+	wg.Wait()
+	return mergeCSVShards(shards)
+}
+
+// workerCount resolves the -workers flag to a usable goroutine count,
+// defaulting to runtime.NumCPU() (logical CPUs) when unset or invalid.
+func workerCount(requested int) int {
+	if requested > 0 {
+		return requested
+	}
+	return runtime.NumCPU()
+}
+
+// runBenchmark times runJSONWorkers over the corpus at path across an
+// increasing worker count (1, 2, 4, ... up to runtime.NumCPU()), printing
+// wall-clock time for each so speedup can be read off directly instead of
+// requiring a go test benchmark harness.
+func runBenchmark(path string) {
+	entries, err := loadJSONEntries(path)
+	if err != nil {
+		fmt.Printf("Benchmark failed to load corpus: %v\n", err)
+		return
+	}
+	fmt.Printf("Benchmarking worker pool over %d documents\n", len(entries))
+
+	htmlTagRegex := regexp.MustCompile(`<[^>]*>`)
+	sentSplit := regexp.MustCompile(`[.!?]+`)
+
+	maxWorkers := runtime.NumCPU()
+	for workers := 1; workers <= maxWorkers; workers *= 2 {
+		start := time.Now()
+		runJSONWorkers(entries, workers, htmlTagRegex, sentSplit)
+		fmt.Printf("  workers=%d  elapsed=%s\n", workers, time.Since(start))
+	}
+	if maxWorkers&(maxWorkers-1) != 0 { // maxWorkers isn't a power of two; also report it directly
+		start := time.Now()
+		runJSONWorkers(entries, maxWorkers, htmlTagRegex, sentSplit)
+		fmt.Printf("  workers=%d  elapsed=%s\n", maxWorkers, time.Since(start))
+	}
+}
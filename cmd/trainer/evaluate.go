@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+// defaultFolds is the default k for stratified k-fold cross-validation.
+const defaultFolds = 5
+
+// classifyThreshold is the ProbScam cutoff used to turn a verdict into a
+// binary Scam/Safe prediction for the confusion matrix.
+const classifyThreshold = 0.5
+
+// ConfusionMatrix tallies classifier outcomes against ground truth, pooled
+// across all cross-validation folds.
+type ConfusionMatrix struct {
+	TP int `json:"tp"`
+	FP int `json:"fp"`
+	TN int `json:"tn"`
+	FN int `json:"fn"`
+}
+
+// ROCPoint is a single (threshold, FPR, TPR) sample of the ROC curve.
+type ROCPoint struct {
+	Threshold float64 `json:"threshold"`
+	FPR       float64 `json:"fpr"`
+	TPR       float64 `json:"tpr"`
+}
+
+// EvaluationReport is the output of the evaluate subcommand.
+type EvaluationReport struct {
+	Folds     int             `json:"folds"`
+	Matrix    ConfusionMatrix `json:"confusion_matrix"`
+	Precision float64         `json:"precision"`
+	Recall    float64         `json:"recall"`
+	F1        float64         `json:"f1"`
+	Accuracy  float64         `json:"accuracy"`
+	MCC       float64         `json:"mcc"`
+	ROC       []ROCPoint      `json:"roc_curve"`
+	AUC       float64         `json:"auc"`
+}
+
+// scoredSample pairs a held-out document's predicted scam probability with
+// its ground-truth label, pooled across folds to build the ROC curve.
+type scoredSample struct {
+	score float64
+	scam  bool
+}
+
+// runEvaluate performs stratified k-fold cross-validation over the JSON
+// corpus at path, training the word-stats Naive Bayes model on each fold's
+// training split and scoring its held-out split, then writes
+// evaluation_report.json.
+func runEvaluate(path string, k int) {
+	entries, err := loadJSONEntries(path)
+	if err != nil {
+		log.Fatalf("Loading corpus failed: %v", err)
+	}
+	if k < 2 {
+		k = defaultFolds
+	}
+
+	folds := stratifiedFolds(entries, k)
+
+	var matrix ConfusionMatrix
+	var samples []scoredSample
+
+	for i, testIdx := range folds {
+		test := make(map[int]bool, len(testIdx))
+		for _, idx := range testIdx {
+			test[idx] = true
+		}
+
+		var trainEntries, testEntries []JSONEntry
+		for idx, e := range entries {
+			if test[idx] {
+				testEntries = append(testEntries, e)
+			} else {
+				trainEntries = append(trainEntries, e)
+			}
+		}
+
+		safeWordCounts, scamWordCounts, safeCount, scamCount := buildWordCounts(trainEntries)
+		model := buildTrainingOutput(safeWordCounts, scamWordCounts, safeCount, scamCount)
+
+		for _, e := range testEntries {
+			verdict := classify(model, e.Text, "", DefaultClassifyOptions())
+			actualScam := e.Label == 1
+			predictedScam := verdict.ProbScam >= classifyThreshold
+
+			switch {
+			case predictedScam && actualScam:
+				matrix.TP++
+			case predictedScam && !actualScam:
+				matrix.FP++
+			case !predictedScam && actualScam:
+				matrix.FN++
+			default:
+				matrix.TN++
+			}
+
+			samples = append(samples, scoredSample{score: verdict.ProbScam, scam: actualScam})
+		}
+
+		fmt.Printf("Fold %d/%d complete (%d train, %d test)\n", i+1, len(folds), len(trainEntries), len(testEntries))
+	}
+
+	report := EvaluationReport{
+		Folds:     len(folds),
+		Matrix:    matrix,
+		Precision: precision(matrix),
+		Recall:    recall(matrix),
+		F1:        f1Score(matrix),
+		Accuracy:  accuracy(matrix),
+		MCC:       matthewsCorrCoef(matrix),
+	}
+	report.ROC, report.AUC = rocCurve(samples, 100)
+
+	outPath := "../../data/evaluation_report.json"
+	if err := writeJSON(outPath, report); err != nil {
+		log.Printf("Warning: could not write %s: %v", outPath, err)
+		return
+	}
+	fmt.Printf("Done! Evaluation report written to %s\n", outPath)
+}
+
+// stratifiedFolds partitions entries' indices into k folds, distributing
+// Safe and Scam documents round-robin within their own label so every fold
+// keeps roughly the corpus's original class ratio.
+func stratifiedFolds(entries []JSONEntry, k int) [][]int {
+	var safeIdx, scamIdx []int
+	for idx, e := range entries {
+		if e.Label == 1 {
+			scamIdx = append(scamIdx, idx)
+		} else {
+			safeIdx = append(safeIdx, idx)
+		}
+	}
+
+	folds := make([][]int, k)
+	for i, idx := range safeIdx {
+		folds[i%k] = append(folds[i%k], idx)
+	}
+	for i, idx := range scamIdx {
+		folds[i%k] = append(folds[i%k], idx)
+	}
+	return folds
+}
+
+func precision(m ConfusionMatrix) float64 {
+	if m.TP+m.FP == 0 {
+		return 0
+	}
+	return float64(m.TP) / float64(m.TP+m.FP)
+}
+
+func recall(m ConfusionMatrix) float64 {
+	if m.TP+m.FN == 0 {
+		return 0
+	}
+	return float64(m.TP) / float64(m.TP+m.FN)
+}
+
+func f1Score(m ConfusionMatrix) float64 {
+	p, r := precision(m), recall(m)
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+func accuracy(m ConfusionMatrix) float64 {
+	total := m.TP + m.FP + m.TN + m.FN
+	if total == 0 {
+		return 0
+	}
+	return float64(m.TP+m.TN) / float64(total)
+}
+
+// matthewsCorrCoef computes the Matthews correlation coefficient, a balanced
+// measure even when the Safe/Scam classes are imbalanced.
+func matthewsCorrCoef(m ConfusionMatrix) float64 {
+	tp, fp, tn, fn := float64(m.TP), float64(m.FP), float64(m.TN), float64(m.FN)
+	denominator := (tp + fp) * (tp + fn) * (tn + fp) * (tn + fn)
+	if denominator == 0 {
+		return 0
+	}
+	return (tp*tn - fp*fn) / math.Sqrt(denominator)
+}
+
+// rocCurve samples the ROC curve at numThresholds+1 evenly spaced cutoffs
+// from 1 down to 0 inclusive, returning the curve and its area under it via the
+// trapezoid rule.
+func rocCurve(samples []scoredSample, numThresholds int) ([]ROCPoint, float64) {
+	var totalPos, totalNeg int
+	for _, s := range samples {
+		if s.scam {
+			totalPos++
+		} else {
+			totalNeg++
+		}
+	}
+
+	points := make([]ROCPoint, 0, numThresholds+1)
+	for i := 0; i <= numThresholds; i++ {
+		threshold := 1 - float64(i)/float64(numThresholds)
+
+		var tp, fp int
+		for _, s := range samples {
+			if s.score >= threshold {
+				if s.scam {
+					tp++
+				} else {
+					fp++
+				}
+			}
+		}
+
+		tpr, fpr := 0.0, 0.0
+		if totalPos > 0 {
+			tpr = float64(tp) / float64(totalPos)
+		}
+		if totalNeg > 0 {
+			fpr = float64(fp) / float64(totalNeg)
+		}
+		points = append(points, ROCPoint{Threshold: threshold, FPR: fpr, TPR: tpr})
+	}
+
+	sort.Slice(points, func(i, j int) bool { return points[i].FPR < points[j].FPR })
+
+	var auc float64
+	for i := 1; i < len(points); i++ {
+		width := points[i].FPR - points[i-1].FPR
+		avgHeight := (points[i].TPR + points[i-1].TPR) / 2
+		auc += width * avgHeight
+	}
+
+	return points, auc
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// irrelevantScoreThreshold is the default chi-square cutoff below which a
+// word is considered noise (no real association with either class) and
+// written to irrelevant_words.json.
+const irrelevantScoreThreshold = 3.841 // chi-square critical value, p=0.05, df=1
+
+// FeatureScore is one word's chi-square association with the Safe/Scam
+// label, used both to rank the most discriminative words per class and to
+// find words below irrelevantScoreThreshold.
+type FeatureScore struct {
+	Word      string  `json:"word"`
+	ChiSquare float64 `json:"chi_square"`
+	SafeCount int     `json:"safe_count"`
+	ScamCount int     `json:"scam_count"`
+}
+
+// FeaturesReport is the output of the `features` subcommand.
+type FeaturesReport struct {
+	TopScamWords []FeatureScore `json:"top_scam_words"`
+	TopSafeWords []FeatureScore `json:"top_safe_words"`
+	Irrelevant   []string       `json:"irrelevant_words"`
+	Threshold    float64        `json:"threshold"`
+}
+
+// chiSquare computes the 2x2 contingency chi-square statistic for a word's
+// association with the Scam label. A = present & scam, B = present & safe,
+// C = absent & scam, D = absent & safe.
+func chiSquare(scamWithWord, safeWithWord, totalScam, totalSafe int) float64 {
+	a := float64(scamWithWord)
+	b := float64(safeWithWord)
+	c := float64(totalScam - scamWithWord)
+	d := float64(totalSafe - safeWithWord)
+	n := a + b + c + d
+
+	denominator := (a + b) * (c + d) * (a + c) * (b + d)
+	if denominator == 0 {
+		return 0
+	}
+	numerator := n * (a*d - b*c) * (a*d - b*c)
+	return numerator / denominator
+}
+
+// buildFeaturesReport computes chi-square feature selection over
+// safeWordCounts/scamWordCounts (already built by analyzeJSON's per-word
+// document frequency pass), ranking the topK most discriminative words per
+// class and collecting every word scoring below threshold as irrelevant.
+func buildFeaturesReport(safeWordCounts, scamWordCounts map[string]int, totalSafe, totalScam int, topK int, threshold float64) FeaturesReport {
+	seen := make(map[string]bool, len(safeWordCounts)+len(scamWordCounts))
+	for w := range safeWordCounts {
+		seen[w] = true
+	}
+	for w := range scamWordCounts {
+		seen[w] = true
+	}
+
+	var scores []FeatureScore
+	for w := range seen {
+		safe := safeWordCounts[w]
+		scam := scamWordCounts[w]
+		scores = append(scores, FeatureScore{
+			Word:      w,
+			ChiSquare: chiSquare(scam, safe, totalScam, totalSafe),
+			SafeCount: safe,
+			ScamCount: scam,
+		})
+	}
+
+	report := FeaturesReport{Threshold: threshold}
+
+	scamSorted := append([]FeatureScore(nil), scores...)
+	sort.Slice(scamSorted, func(i, j int) bool {
+		if scamSorted[i].ChiSquare != scamSorted[j].ChiSquare {
+			return scamSorted[i].ChiSquare > scamSorted[j].ChiSquare
+		}
+		return scamSorted[i].ScamCount > scamSorted[j].ScamCount
+	})
+	for _, s := range scamSorted {
+		if s.ScamCount <= s.SafeCount {
+			continue
+		}
+		report.TopScamWords = append(report.TopScamWords, s)
+		if len(report.TopScamWords) == topK {
+			break
+		}
+	}
+
+	safeSorted := append([]FeatureScore(nil), scores...)
+	sort.Slice(safeSorted, func(i, j int) bool {
+		if safeSorted[i].ChiSquare != safeSorted[j].ChiSquare {
+			return safeSorted[i].ChiSquare > safeSorted[j].ChiSquare
+		}
+		return safeSorted[i].SafeCount > safeSorted[j].SafeCount
+	})
+	for _, s := range safeSorted {
+		if s.SafeCount <= s.ScamCount {
+			continue
+		}
+		report.TopSafeWords = append(report.TopSafeWords, s)
+		if len(report.TopSafeWords) == topK {
+			break
+		}
+	}
+
+	for _, s := range scores {
+		if s.ChiSquare < threshold {
+			report.Irrelevant = append(report.Irrelevant, s.Word)
+		}
+	}
+	sort.Strings(report.Irrelevant)
+
+	return report
+}
+
+// runFeatures computes the features report over safeWordCounts/
+// scamWordCounts and writes irrelevant_words.json plus the full report,
+// printing where each landed.
+func runFeatures(safeWordCounts, scamWordCounts map[string]int, totalSafe, totalScam int, topK int, threshold float64) {
+	report := buildFeaturesReport(safeWordCounts, scamWordCounts, totalSafe, totalScam, topK, threshold)
+
+	outPath := "../../data/feature_report.json"
+	if err := writeJSON(outPath, report); err != nil {
+		log.Printf("Warning: could not write %s: %v", outPath, err)
+	} else {
+		fmt.Printf("Done! Feature selection report written to %s\n", outPath)
+	}
+
+	irrelevantPath := "../../data/irrelevant_words.json"
+	if err := writeJSON(irrelevantPath, report.Irrelevant); err != nil {
+		log.Printf("Warning: could not write %s: %v", irrelevantPath, err)
+	} else {
+		fmt.Printf("Done! %d irrelevant words written to %s\n", len(report.Irrelevant), irrelevantPath)
+	}
+}
+
+func writeJSON(path string, v interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// buildWordCounts tallies per-document word occurrence counts by label,
+// using analyzeJSON's wordRegex, length, dictionary and stopWords filters,
+// for the features subcommand's chi-square pass. Unlike analyzeJSON it does
+// not strip HTML tags, drop numeric tokens, or subsample long documents.
+func buildWordCounts(entries []JSONEntry) (safeWordCounts, scamWordCounts map[string]int, safeCount, scamCount int) {
+	safeWordCounts = make(map[string]int)
+	scamWordCounts = make(map[string]int)
+
+	for _, entry := range entries {
+		words := wordRegex.FindAllString(strings.ToLower(entry.Text), -1)
+		seen := make(map[string]bool, len(words))
+		for _, w := range words {
+			if len(w) < 3 || seen[w] {
+				continue
+			}
+			seen[w] = true
+			if len(validEnglishWords) > 0 && !validEnglishWords[w] {
+				continue
+			}
+			if stopWords[w] {
+				continue
+			}
+			if entry.Label == 1 {
+				scamWordCounts[w]++
+			} else {
+				safeWordCounts[w]++
+			}
+		}
+		if entry.Label == 1 {
+			scamCount++
+		} else {
+			safeCount++
+		}
+	}
+	return safeWordCounts, scamWordCounts, safeCount, scamCount
+}
+
+// irrelevantWords is a machine-generated override for the compiled-in
+// phishingIrrelevant map, loaded via -irrelevant-file from a prior `features`
+// run. Nil until loaded, in which case isIrrelevant falls back to
+// phishingIrrelevant.
+var irrelevantWords map[string]bool
+
+// isIrrelevant reports whether w should be excluded as a non-discriminative
+// token, preferring a loaded irrelevantWords override over the compiled-in
+// phishingIrrelevant map.
+func isIrrelevant(w string) bool {
+	if irrelevantWords != nil {
+		return irrelevantWords[w]
+	}
+	return phishingIrrelevant[w]
+}
+
+// loadIrrelevantWords reads a machine-generated irrelevant_words.json (from
+// the `features` subcommand) to use in place of the compiled-in
+// phishingIrrelevant map. Returns nil if path is empty or unreadable, so
+// callers can fall back to the built-in list.
+func loadIrrelevantWords(path string) map[string]bool {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("Warning: could not read irrelevant words file %s: %v", path, err)
+		return nil
+	}
+
+	var words []string
+	if err := json.Unmarshal(data, &words); err != nil {
+		log.Printf("Warning: could not parse irrelevant words file %s: %v", path, err)
+		return nil
+	}
+
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
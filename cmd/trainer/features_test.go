@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+// TestBuildFeaturesReportRanksObviousScamMarkers verifies that over a toy
+// corpus, words that appear almost exclusively in scam documents ("winner",
+// "lottery") float to the top of TopScamWords ahead of a word that appears
+// in both classes about equally ("meeting"), and that a word appearing
+// only in safe documents ("invoice") never appears in TopScamWords at all.
+func TestBuildFeaturesReportRanksObviousScamMarkers(t *testing.T) {
+	safeWordCounts := map[string]int{
+		"meeting": 8,
+		"invoice": 10,
+	}
+	scamWordCounts := map[string]int{
+		"winner":  10,
+		"lottery": 9,
+		"meeting": 7,
+	}
+	totalSafe, totalScam := 10, 10
+
+	report := buildFeaturesReport(safeWordCounts, scamWordCounts, totalSafe, totalScam, 10, irrelevantScoreThreshold)
+
+	if len(report.TopScamWords) < 2 {
+		t.Fatalf("TopScamWords = %v, want at least 2 entries", report.TopScamWords)
+	}
+	top2 := map[string]bool{report.TopScamWords[0].Word: true, report.TopScamWords[1].Word: true}
+	if !top2["winner"] || !top2["lottery"] {
+		t.Errorf("TopScamWords = %v, want \"winner\" and \"lottery\" ranked highest", report.TopScamWords)
+	}
+
+	for _, s := range report.TopScamWords {
+		if s.Word == "invoice" {
+			t.Errorf("TopScamWords = %v, \"invoice\" (safe-only) should never appear", report.TopScamWords)
+		}
+	}
+
+	for i, s := range report.TopScamWords {
+		if s.Word == "meeting" {
+			for j, other := range report.TopScamWords {
+				if (other.Word == "winner" || other.Word == "lottery") && j > i {
+					t.Errorf("TopScamWords = %v, \"meeting\" should rank below the scam-only markers", report.TopScamWords)
+				}
+			}
+		}
+	}
+}
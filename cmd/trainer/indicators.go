@@ -0,0 +1,120 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// indicatorTopN is how many words make each of TopScamIndicators/
+// TopSafeIndicators.
+const indicatorTopN = 25
+
+// defaultIndicatorMinCount is the minimum combined Safe+Scam document count
+// a word needs before its log-likelihood ratio is considered, suppressing
+// noise from words seen only once or twice.
+const defaultIndicatorMinCount = 5
+
+// IndicatorScore is one word's log-likelihood-ratio association with the
+// Scam or Safe class, used to rank genuinely discriminative words instead
+// of merely frequent ones.
+type IndicatorScore struct {
+	Word      string  `json:"word"`
+	LLR       float64 `json:"llr"`
+	ScamCount int     `json:"scam_count"`
+	SafeCount int     `json:"safe_count"`
+}
+
+// oLogOE returns o*log(o/e), defined as 0 when o<=0 or e<=0 (the standard
+// convention for G-test sums where a zero observed count contributes
+// nothing).
+func oLogOE(o, e float64) float64 {
+	if o <= 0 || e <= 0 {
+		return 0
+	}
+	return o * math.Log(o/e)
+}
+
+// logLikelihoodRatio computes the G-test statistic for word counts s (scam)
+// and h (safe) against class totals scamTotal/safeTotal, following Dunning's
+// log-likelihood ratio for word association. The sign is positive when the
+// word is over-represented in scam mail relative to safe mail, negative
+// otherwise.
+func logLikelihoodRatio(s, h int, scamTotal, safeTotal float64) float64 {
+	sf, hf := float64(s), float64(h)
+	total := scamTotal + safeTotal
+	n := sf + hf
+	if n == 0 || total == 0 {
+		return 0
+	}
+
+	expectedScam := n * scamTotal / total
+	expectedSafe := n * safeTotal / total
+	llr := 2 * (oLogOE(sf, expectedScam) + oLogOE(hf, expectedSafe))
+
+	if scamTotal > 0 && safeTotal > 0 && sf/scamTotal < hf/safeTotal {
+		llr = -llr
+	}
+	return llr
+}
+
+// topIndicators ranks every word appearing in safeWordCounts/scamWordCounts
+// by log-likelihood ratio, returning the topN most scam-leaning and
+// safe-leaning words with at least minCount combined support.
+func topIndicators(safeWordCounts, scamWordCounts map[string]int, minCount, topN int) (topScam, topSafe []IndicatorScore) {
+	var scamTotal, safeTotal float64
+	for _, c := range scamWordCounts {
+		scamTotal += float64(c)
+	}
+	for _, c := range safeWordCounts {
+		safeTotal += float64(c)
+	}
+
+	seen := make(map[string]bool, len(safeWordCounts)+len(scamWordCounts))
+	for w := range safeWordCounts {
+		seen[w] = true
+	}
+	for w := range scamWordCounts {
+		seen[w] = true
+	}
+
+	var scores []IndicatorScore
+	for w := range seen {
+		safe := safeWordCounts[w]
+		scam := scamWordCounts[w]
+		if safe+scam < minCount {
+			continue
+		}
+		scores = append(scores, IndicatorScore{
+			Word:      w,
+			LLR:       logLikelihoodRatio(scam, safe, scamTotal, safeTotal),
+			ScamCount: scam,
+			SafeCount: safe,
+		})
+	}
+
+	scamSorted := append([]IndicatorScore(nil), scores...)
+	sort.Slice(scamSorted, func(i, j int) bool { return scamSorted[i].LLR > scamSorted[j].LLR })
+	for _, s := range scamSorted {
+		if s.LLR <= 0 {
+			break
+		}
+		topScam = append(topScam, s)
+		if len(topScam) == topN {
+			break
+		}
+	}
+
+	safeSorted := append([]IndicatorScore(nil), scores...)
+	sort.Slice(safeSorted, func(i, j int) bool { return safeSorted[i].LLR < safeSorted[j].LLR })
+	for _, s := range safeSorted {
+		if s.LLR >= 0 {
+			break
+		}
+		topSafe = append(topSafe, s)
+		if len(topSafe) == topN {
+			break
+		}
+	}
+
+	return topScam, topSafe
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// isolationForestNumTrees/isolationForestSampleSize are the standard
+// Isolation Forest defaults (Liu, Ting & Zhou 2008): 100 trees, each built
+// from a subsample of 256 points, is enough for the anomaly score to
+// converge without the tree count dominating runtime.
+const isolationForestNumTrees = 100
+const isolationForestSampleSize = 256
+
+// outlierTopM is how many of a class's most anomalous emails are reported.
+const outlierTopM = 10
+
+// outlierCSVCap bounds how many per-row feature vectors the (streaming)
+// CSV path retains per class for outlier detection, since unlike the JSON
+// path it doesn't already hold the whole corpus in memory.
+const outlierCSVCap = 5000
+
+var urlRegex = regexp.MustCompile(`https?://\S+`)
+
+// EmailFeatures is a per-email numeric feature vector: everything the
+// isolation forest needs to judge how unusual one email is relative to the
+// rest of its class.
+type EmailFeatures struct {
+	WordCount          float64
+	AvgSentenceLen     float64
+	ShoutingScore      float64
+	ExclamationCount   float64
+	QuestionCount      float64
+	DollarCount        float64
+	PercentCount       float64
+	UppercaseRunRatio  float64
+	URLCount           float64
+	DigitRatio         float64
+	LongestNonAlnumRun float64
+}
+
+// featureNames labels EmailFeatures.vector()'s slots in the same order, so
+// a reported outlier's feature contributions can be named in JSON.
+var featureNames = []string{
+	"word_count", "avg_sentence_len", "shouting_score", "exclamation_count",
+	"question_count", "dollar_count", "percent_count", "uppercase_run_ratio",
+	"url_count", "digit_ratio", "longest_non_alnum_run",
+}
+
+func (f EmailFeatures) vector() []float64 {
+	return []float64{
+		f.WordCount, f.AvgSentenceLen, f.ShoutingScore, f.ExclamationCount,
+		f.QuestionCount, f.DollarCount, f.PercentCount, f.UppercaseRunRatio,
+		f.URLCount, f.DigitRatio, f.LongestNonAlnumRun,
+	}
+}
+
+// extractFeatures computes cleanText's feature vector, reusing the same
+// sentence-splitting convention (sentences longer than 10 trimmed chars)
+// the rest of the analyzer uses.
+func extractFeatures(cleanText string, sentSplit *regexp.Regexp) EmailFeatures {
+	wordCount := len(wordRegex.FindAllString(strings.ToLower(cleanText), -1))
+
+	sentCount := 0
+	for _, s := range sentSplit.Split(cleanText, -1) {
+		if len(strings.TrimSpace(s)) > 10 {
+			sentCount++
+		}
+	}
+	if sentCount == 0 {
+		sentCount = 1
+	}
+
+	var exclam, question, dollar, percent, digits float64
+	var upperRun, longestUpperRun, nonAlnumRun, longestNonAlnumRun int
+	totalChars := 0
+	for _, r := range cleanText {
+		totalChars++
+		switch r {
+		case '!':
+			exclam++
+		case '?':
+			question++
+		case '$':
+			dollar++
+		case '%':
+			percent++
+		}
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+
+		if unicode.IsUpper(r) {
+			upperRun++
+			if upperRun > longestUpperRun {
+				longestUpperRun = upperRun
+			}
+		} else {
+			upperRun = 0
+		}
+
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r) {
+			nonAlnumRun++
+			if nonAlnumRun > longestNonAlnumRun {
+				longestNonAlnumRun = nonAlnumRun
+			}
+		} else {
+			nonAlnumRun = 0
+		}
+	}
+
+	uppercaseRunRatio, digitRatio := 0.0, 0.0
+	if totalChars > 0 {
+		uppercaseRunRatio = float64(longestUpperRun) / float64(totalChars)
+		digitRatio = digits / float64(totalChars)
+	}
+
+	return EmailFeatures{
+		WordCount:          float64(wordCount),
+		AvgSentenceLen:     float64(wordCount) / float64(sentCount),
+		ShoutingScore:      calculateShoutingScore(cleanText),
+		ExclamationCount:   exclam,
+		QuestionCount:      question,
+		DollarCount:        dollar,
+		PercentCount:       percent,
+		UppercaseRunRatio:  uppercaseRunRatio,
+		URLCount:           float64(len(urlRegex.FindAllString(cleanText, -1))),
+		DigitRatio:         digitRatio,
+		LongestNonAlnumRun: float64(longestNonAlnumRun),
+	}
+}
+
+// iTreeNode is one node of an isolation tree: an internal split node, or a
+// leaf recording how many samples reached it (needed for the c(size)
+// path-length adjustment at leaves holding more than one point).
+type iTreeNode struct {
+	isLeaf      bool
+	size        int
+	feature     int
+	splitVal    float64
+	left, right *iTreeNode
+}
+
+// buildITree grows one isolation tree from data: at every node it picks a
+// random feature and a random split value within that feature's [min,max]
+// over data, recursing until maxDepth is reached or one point remains.
+func buildITree(data [][]float64, depth, maxDepth int, rng *rand.Rand) *iTreeNode {
+	if depth >= maxDepth || len(data) <= 1 {
+		return &iTreeNode{isLeaf: true, size: len(data)}
+	}
+
+	feature := rng.Intn(len(data[0]))
+	min, max := data[0][feature], data[0][feature]
+	for _, x := range data {
+		if x[feature] < min {
+			min = x[feature]
+		}
+		if x[feature] > max {
+			max = x[feature]
+		}
+	}
+	if min == max {
+		return &iTreeNode{isLeaf: true, size: len(data)}
+	}
+	splitVal := min + rng.Float64()*(max-min)
+
+	var left, right [][]float64
+	for _, x := range data {
+		if x[feature] < splitVal {
+			left = append(left, x)
+		} else {
+			right = append(right, x)
+		}
+	}
+	if len(left) == 0 || len(right) == 0 {
+		return &iTreeNode{isLeaf: true, size: len(data)}
+	}
+
+	return &iTreeNode{
+		feature:  feature,
+		splitVal: splitVal,
+		left:     buildITree(left, depth+1, maxDepth, rng),
+		right:    buildITree(right, depth+1, maxDepth, rng),
+	}
+}
+
+// pathLength returns x's path length through tree, adding the c(size)
+// adjustment at a leaf holding more than one point - the standard
+// isolation-forest correction for paths cut short by maxDepth rather than
+// true isolation.
+func pathLength(tree *iTreeNode, x []float64, depth int) float64 {
+	if tree.isLeaf {
+		if tree.size > 1 {
+			return float64(depth) + cFactor(tree.size)
+		}
+		return float64(depth)
+	}
+	if x[tree.feature] < tree.splitVal {
+		return pathLength(tree.left, x, depth+1)
+	}
+	return pathLength(tree.right, x, depth+1)
+}
+
+// cFactor is c(n) = 2*H(n-1) - 2*(n-1)/n, the average unsuccessful-search
+// path length of a binary search tree over n points - the normalizing
+// constant isolation forest path lengths are measured against.
+func cFactor(n int) float64 {
+	if n <= 1 {
+		return 0
+	}
+	return 2*harmonic(float64(n-1)) - 2*float64(n-1)/float64(n)
+}
+
+// harmonic approximates the nth harmonic number as ln(n) + gamma (the
+// Euler-Mascheroni constant), accurate to within 1/(2n).
+func harmonic(n float64) float64 {
+	const eulerMascheroni = 0.5772156649015329
+	if n <= 0 {
+		return 0
+	}
+	return math.Log(n) + eulerMascheroni
+}
+
+// IsolationForest is an ensemble of isolation trees trained on random
+// subsamples of a class's feature vectors, scoring how anomalous a given
+// vector is relative to that class.
+type IsolationForest struct {
+	trees      []*iTreeNode
+	sampleSize int
+}
+
+// NewIsolationForest builds numTrees isolation trees, each from a random
+// subsample of size sampleSize drawn (with replacement) from data.
+func NewIsolationForest(data [][]float64, numTrees, sampleSize int, rng *rand.Rand) *IsolationForest {
+	if sampleSize > len(data) {
+		sampleSize = len(data)
+	}
+	maxDepth := int(math.Ceil(math.Log2(float64(sampleSize))))
+	if maxDepth < 1 {
+		maxDepth = 1
+	}
+
+	trees := make([]*iTreeNode, numTrees)
+	for i := range trees {
+		sample := make([][]float64, sampleSize)
+		for j := range sample {
+			sample[j] = data[rng.Intn(len(data))]
+		}
+		trees[i] = buildITree(sample, 0, maxDepth, rng)
+	}
+	return &IsolationForest{trees: trees, sampleSize: sampleSize}
+}
+
+// AnomalyScore returns x's isolation-forest anomaly score
+// s(x,psi) = 2^(-E(h(x))/c(psi)): scores near 1 isolate quickly (anomalous),
+// scores near or below 0.5 look like typical points of the trained class.
+func (f *IsolationForest) AnomalyScore(x []float64) float64 {
+	c := cFactor(f.sampleSize)
+	if c == 0 {
+		return 0
+	}
+	var total float64
+	for _, tree := range f.trees {
+		total += pathLength(tree, x, 0)
+	}
+	return math.Pow(2, -(total/float64(len(f.trees)))/c)
+}
+
+// OutlierEmail is one anomalous email surfaced by an isolation forest,
+// with the feature values that drove its score.
+type OutlierEmail struct {
+	Index    int                `json:"index"`
+	Score    float64            `json:"score"`
+	Features map[string]float64 `json:"features"`
+}
+
+// topOutliers trains an isolation forest on features and returns the topM
+// highest-scoring (most anomalous) emails.
+func topOutliers(features []EmailFeatures, topM int, rng *rand.Rand) []OutlierEmail {
+	if len(features) == 0 {
+		return nil
+	}
+
+	data := make([][]float64, len(features))
+	for i, f := range features {
+		data[i] = f.vector()
+	}
+	forest := NewIsolationForest(data, isolationForestNumTrees, isolationForestSampleSize, rng)
+
+	outliers := make([]OutlierEmail, len(features))
+	for i, vec := range data {
+		featureMap := make(map[string]float64, len(vec))
+		for j, v := range vec {
+			featureMap[featureNames[j]] = v
+		}
+		outliers[i] = OutlierEmail{Index: i, Score: forest.AnomalyScore(vec), Features: featureMap}
+	}
+
+	sort.Slice(outliers, func(i, j int) bool { return outliers[i].Score > outliers[j].Score })
+	if topM < len(outliers) {
+		outliers = outliers[:topM]
+	}
+	return outliers
+}
+
+// OutlierReport is the "outliers" section of linguistic_stats.json,
+// letting analysts review the most statistically unusual email in each
+// class - often mislabeled data or a genuinely novel scam pattern.
+type OutlierReport struct {
+	TopScamOutliers []OutlierEmail `json:"top_scam_outliers"`
+	TopSafeOutliers []OutlierEmail `json:"top_safe_outliers"`
+}
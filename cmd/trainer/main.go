@@ -9,11 +9,13 @@ import (
 	"io"
 	"log"
 	"math"
+	"math/rand"
 	"net/http"
 	"os"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"unicode"
 )
 
@@ -139,6 +141,23 @@ var (
 	validEnglishWords = make(map[string]bool)
 )
 
+// sketchMode swaps the exact per-word frequency maps for a Count-Min
+// Sketch (bounded memory, approximate counts) with a Space-Saving
+// heavy-hitters structure preserving an exact top-K, used for corpora too
+// large to hold in a map[string]int.
+var sketchMode bool
+var sketchWidth, sketchDepth uint
+var sketchCapacity int
+
+// approxMode reports a cardinality.Sketch (HyperLogLog) estimate of each
+// class's vocabulary size next to the exact count. The exact per-word
+// map[string]int tables are still built, so it does not bound memory.
+var approxMode bool
+
+// requestedWorkers is the -workers flag value; 0 means "use
+// runtime.NumCPU()", resolved via workerCount.
+var requestedWorkers int
+
 const dictionaryURL = "https://raw.githubusercontent.com/dwyl/english-words/master/words_alpha.txt"
 const dictionaryFile = "../../data/words_alpha.txt"
 
@@ -208,6 +227,26 @@ type WordFreq struct {
 type LinguisticReport struct {
 	SafeStats LinguisticStats `json:"safe_stats"`
 	ScamStats LinguisticStats `json:"scam_stats"`
+
+	// TopScamIndicators/TopSafeIndicators rank words by log-likelihood ratio
+	// rather than raw frequency, surfacing words that actually distinguish
+	// the two classes instead of merely common ones.
+	TopScamIndicators []IndicatorScore `json:"top_scam_indicators"`
+	TopSafeIndicators []IndicatorScore `json:"top_safe_indicators"`
+
+	// TopScamBigrams/TopSafeBigrams surface discriminative two-word phrases
+	// ("wire transfer", "click below") that unigram indicators lose.
+	TopScamBigrams []BigramScore `json:"top_scam_bigrams"`
+	TopSafeBigrams []BigramScore `json:"top_safe_bigrams"`
+
+	// Sketch is only populated in -sketch mode, recording the Count-Min
+	// Sketch/heavy-hitters parameters the (approximate) word counts above
+	// were produced with.
+	Sketch *SketchInfo `json:"sketch_params,omitempty"`
+
+	// Outliers surfaces the most statistically anomalous emails per class,
+	// via an isolation forest over per-email feature vectors.
+	Outliers OutlierReport `json:"outliers"`
 }
 
 // JSONEntry represents a row in combined_reduced.json
@@ -231,9 +270,82 @@ type TrainingOutput struct {
 }
 
 func main() {
-	loadDictionary()
 	filePath := flag.String("file", "", "Path to the input file (.csv or .json)")
+	classify := flag.Bool("classify", false, "Score a single email against a trained word_stats.json model")
+	modelPath := flag.String("model", "../../data/word_stats.json", "Path to the word_stats.json model (used with -classify)")
+	subjectPath := flag.String("subject", "", "Path to a separate subject-line file, weighted higher than body text (used with -classify)")
+	alpha := flag.Float64("alpha", 1.0, "Laplace smoothing constant for word probabilities (used with -classify)")
+	minWordCount := flag.Int("min-count", 1, "Minimum combined Safe+Scam document count for a word to count as vocabulary (used with -classify)")
+	subjectWeight := flag.Float64("subject-weight", 2.0, "Log-likelihood weight given to subject tokens relative to body tokens (used with -classify)")
+	chargram := flag.Bool("chargram", false, "Train the char-n-gram logistic regression model (writes clf.gob) instead of the linguistic report")
+	features := flag.Bool("features", false, "Run chi-square feature selection over a JSON corpus, writing feature_report.json and irrelevant_words.json")
+	topK := flag.Int("topk", 25, "Number of top discriminative words per class to report (used with -features)")
+	threshold := flag.Float64("threshold", irrelevantScoreThreshold, "Chi-square cutoff below which a word is considered irrelevant (used with -features)")
+	evaluate := flag.Bool("evaluate", false, "Run stratified k-fold cross-validation over a JSON corpus, writing evaluation_report.json")
+	folds := flag.Int("folds", defaultFolds, "Number of cross-validation folds (used with -evaluate)")
+	flag.IntVar(&requestedWorkers, "workers", 0, "Number of worker goroutines for corpus processing (default: runtime.NumCPU())")
+	bench := flag.Bool("bench", false, "Benchmark the worker pool over a JSON corpus at increasing worker counts instead of analyzing it")
+	irrelevantFile := flag.String("irrelevant-file", "", "Path to a machine-generated irrelevant_words.json to use instead of the compiled-in noise list")
+	flag.BoolVar(&approxMode, "approx", false, "Also print a HyperLogLog estimate of per-class vocabulary size next to the exact count (the exact word table is still built)")
+	flag.BoolVar(&sketchMode, "sketch", false, "Count per-word frequencies with a Count-Min Sketch + Space-Saving heavy hitters instead of an exact word table (used with the JSON analysis path)")
+	var sketchWidthFlag, sketchDepthFlag uint
+	flag.UintVar(&sketchWidthFlag, "sketch-width", defaultSketchWidth, "Count-Min Sketch width (counters per row, used with -sketch)")
+	flag.UintVar(&sketchDepthFlag, "sketch-depth", defaultSketchDepth, "Count-Min Sketch depth (number of independent rows, used with -sketch)")
+	flag.IntVar(&sketchCapacity, "sketch-capacity", defaultSketchCapacity, "Heavy-hitters capacity: how many top words stay exact under -sketch")
+	flag.IntVar(&subsampleThreshold, "subsample-limit", defaultSubsampleLimit, "Cap a document's tokens to a random sample of this size before counting unique words (padding-attack resistance); 0 disables sampling")
+	flag.Int64Var(&subsampleSeed, "seed", defaultSubsampleSeed, "Seed for the token subsampling RNG, for reproducible runs")
 	flag.Parse()
+	sketchWidth, sketchDepth = sketchWidthFlag, sketchDepthFlag
+	loadDictionary()
+	irrelevantWords = loadIrrelevantWords(*irrelevantFile)
+
+	if *chargram {
+		if *filePath == "" {
+			log.Fatal("Please provide a JSON corpus using -file")
+		}
+		if err := TrainCharNGram(*filePath); err != nil {
+			log.Fatalf("Char-n-gram training failed: %v", err)
+		}
+		return
+	}
+
+	if *features {
+		if *filePath == "" {
+			log.Fatal("Please provide a JSON corpus using -file")
+		}
+		entries, err := loadJSONEntries(*filePath)
+		if err != nil {
+			log.Fatalf("Loading corpus failed: %v", err)
+		}
+		safeWordCounts, scamWordCounts, safeCount, scamCount := buildWordCounts(entries)
+		runFeatures(safeWordCounts, scamWordCounts, safeCount, scamCount, *topK, *threshold)
+		return
+	}
+
+	if *evaluate {
+		if *filePath == "" {
+			log.Fatal("Please provide a JSON corpus using -file")
+		}
+		runEvaluate(*filePath, *folds)
+		return
+	}
+
+	if *bench {
+		if *filePath == "" {
+			log.Fatal("Please provide a JSON corpus using -file")
+		}
+		runBenchmark(*filePath)
+		return
+	}
+
+	if *classify {
+		if *filePath == "" {
+			log.Fatal("Please provide the email to classify using -file")
+		}
+		opts := ClassifyOptions{Alpha: *alpha, MinCount: *minWordCount, SubjectWeight: *subjectWeight}
+		runClassify(*modelPath, *filePath, *subjectPath, opts)
+		return
+	}
 
 	if *filePath == "" {
 		// Default to Nazario.csv if present and no flag
@@ -254,108 +366,56 @@ func main() {
 
 func analyzeJSON(filePath string) {
 	fmt.Printf("Analyzing JSON file: %s for linguistic features...\n", filePath)
-	f, err := os.Open(filePath)
-	if err != nil {
-		log.Fatalf("Unable to open file: %v", err)
-	}
-	defer f.Close()
 
-	dec := json.NewDecoder(f)
-	_, err = dec.Token() // Open bracket
+	entries, err := loadJSONEntries(filePath)
 	if err != nil {
-		log.Fatal(err)
+		log.Fatalf("Unable to open file: %v", err)
 	}
 
-	// Accumulators
-	safeWordCounts := make(map[string]int)
-	scamWordCounts := make(map[string]int)
-
-	var safeWordSum, scamWordSum int
-	var safeSentSum, scamSentSum int
-	var safeShoutSum, scamShoutSum float64
-	var safeCount, scamCount int
-
 	htmlTagRegex := regexp.MustCompile(`<[^>]*>`)
 	// Simple sentence approximation (split by . ! ?)
 	sentSplit := regexp.MustCompile(`[.!?]+`)
 
-	count := 0
-	for dec.More() {
-		var entry JSONEntry
-		if err := dec.Decode(&entry); err != nil {
-			log.Printf("Error decoding entry: %v", err)
-			continue
-		}
-		count++
-		if count%1000 == 0 {
-			fmt.Printf("Processed %d emails...\r", count)
-		}
-
-		// 1. Preprocessing
-		cleanText := htmlTagRegex.ReplaceAllString(entry.Text, " ")
-		textLower := strings.ToLower(cleanText)
-
-		// 2. Lingustic Features
-
-		// Words
-		words := wordRegex.FindAllString(textLower, -1)
-		currWordCount := 0
-		uniqueWordsInDoc := make(map[string]bool)
-
-		for _, w := range words {
-			if len(w) < 3 || isNumeric(w) {
-				continue
-			}
-			if len(validEnglishWords) > 0 && !validEnglishWords[w] {
-				continue
-			}
-			if stopWords[w] {
-				continue
-			}
-
-			currWordCount++
-			uniqueWordsInDoc[w] = true
-		}
-
-		// Update global document frequency counts
-		for w := range uniqueWordsInDoc {
-			if entry.Label == 1 {
-				scamWordCounts[w]++
-			} else {
-				safeWordCounts[w]++
-			}
-		}
-
-		// Sentences
-		sentences := sentSplit.Split(cleanText, -1)
-		currSentCount := 0
-		for _, s := range sentences {
-			if len(strings.TrimSpace(s)) > 10 {
-				currSentCount++
-			}
-		}
-		if currSentCount == 0 {
-			currSentCount = 1
-		} // avoid div by zero issues later
-
-		// Shouting
-		shoutScore := calculateShoutingScore(cleanText)
-
-		// 3. Accumulate
-		if entry.Label == 1 {
-			scamCount++
-			scamWordSum += currWordCount
-			scamSentSum += currWordCount / currSentCount // Approx avg sentence length (words/sentence) for this doc
-			scamShoutSum += shoutScore
+	numWorkers := workerCount(requestedWorkers)
+	fmt.Printf("Processing %d emails across %d workers...\n", len(entries), numWorkers)
+	merged := runJSONWorkers(entries, numWorkers, htmlTagRegex, sentSplit)
+
+	safeWordCounts := merged.safeWordCounts
+	scamWordCounts := merged.scamWordCounts
+	safeWordSum, scamWordSum := merged.safeWordSum, merged.scamWordSum
+	safeSentSum, scamSentSum := merged.safeSentSum, merged.scamSentSum
+	safeShoutSum, scamShoutSum := merged.safeShoutSum, merged.scamShoutSum
+	safeCount, scamCount := merged.safeCount, merged.scamCount
+
+	var sketchInfo *SketchInfo
+	if sketchMode {
+		safeWordCounts = materializeFromHeavyHitters(merged.safeHH, merged.safeCMS, sketchCapacity)
+		scamWordCounts = materializeFromHeavyHitters(merged.scamHH, merged.scamCMS, sketchCapacity)
+		sketchInfo = buildSketchInfo(merged.safeCMS, sketchCapacity)
+		fmt.Printf("Sketch mode: Count-Min %dx%d, %d heavy hitters tracked per class (epsilon=%.5f, delta=%.5f)\n",
+			sketchInfo.Depth, sketchInfo.Width, sketchInfo.HeavyHitterCapacity, sketchInfo.Epsilon, sketchInfo.Delta)
+	}
+
+	fmt.Println("Scoring emails with an isolation forest for outlier detection...")
+	var safeFeatures, scamFeatures []EmailFeatures
+	for _, e := range entries {
+		cleanText := htmlTagRegex.ReplaceAllString(e.Text, " ")
+		feat := extractFeatures(cleanText, sentSplit)
+		if e.Label == 1 {
+			scamFeatures = append(scamFeatures, feat)
 		} else {
-			safeCount++
-			safeWordSum += currWordCount
-			safeSentSum += currWordCount / currSentCount
-			safeShoutSum += shoutScore
+			safeFeatures = append(safeFeatures, feat)
 		}
 	}
+	outlierReport := OutlierReport{
+		TopScamOutliers: topOutliers(scamFeatures, outlierTopM, rand.New(rand.NewSource(subsampleSeed+1_000_000))),
+		TopSafeOutliers: topOutliers(safeFeatures, outlierTopM, rand.New(rand.NewSource(subsampleSeed+2_000_000))),
+	}
 
-	_, err = dec.Token() // Close bracket
+	if approxMode {
+		fmt.Printf("Approx vocabulary size - Safe: %d (exact: %d), Scam: %d (exact: %d)\n",
+			merged.safeVocabSketch.Estimate(), len(safeWordCounts), merged.scamVocabSketch.Estimate(), len(scamWordCounts))
+	}
 
 	fmt.Println("\nGenerating linguistic report...")
 
@@ -381,7 +441,17 @@ func analyzeJSON(filePath string) {
 		return wordList[:topN]
 	}
 
+	topScamIndicators, topSafeIndicators := topIndicators(safeWordCounts, scamWordCounts, defaultIndicatorMinCount, indicatorTopN)
+	topScamBigrams, topSafeBigrams := topBigrams(merged.safeBigrams, merged.scamBigrams, defaultIndicatorMinCount, bigramTopN)
+	writeBigramModels(merged.safeBigrams, merged.scamBigrams)
+
 	report := LinguisticReport{
+		TopScamIndicators: topScamIndicators,
+		TopSafeIndicators: topSafeIndicators,
+		TopScamBigrams:    topScamBigrams,
+		TopSafeBigrams:    topSafeBigrams,
+		Sketch:            sketchInfo,
+		Outliers:          outlierReport,
 		SafeStats: LinguisticStats{
 			TotalEmails:       safeCount,
 			AvgWordCount:      float64(safeWordSum) / float64(safeCount),
@@ -412,6 +482,59 @@ func analyzeJSON(filePath string) {
 		log.Fatal(err)
 	}
 	fmt.Println("Done! Linguistic analysis written to linguistic_stats.json")
+
+	writeWordStats(safeWordCounts, scamWordCounts, safeCount, scamCount)
+}
+
+// writeWordStats turns the per-word Safe/Scam document counts already
+// accumulated by analyzeJSON or analyzeCSV into the word_stats.json model the classify
+// subcommand scores new mail against.
+func writeWordStats(safeWordCounts, scamWordCounts map[string]int, safeCount, scamCount int) {
+	output := buildTrainingOutput(safeWordCounts, scamWordCounts, safeCount, scamCount)
+
+	outFile, err := os.Create("../../data/word_stats.json")
+	if err != nil {
+		log.Printf("Warning: could not write word_stats.json: %v", err)
+		return
+	}
+	defer outFile.Close()
+
+	enc := json.NewEncoder(outFile)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(output); err != nil {
+		log.Printf("Warning: could not encode word_stats.json: %v", err)
+		return
+	}
+	fmt.Println("Done! Word statistics written to word_stats.json")
+}
+
+// buildTrainingOutput turns per-word Safe/Scam document counts into the
+// TrainingOutput shape consumed by classify, shared by writeWordStats and
+// the evaluate subcommand's per-fold training step.
+func buildTrainingOutput(safeWordCounts, scamWordCounts map[string]int, safeCount, scamCount int) TrainingOutput {
+	wordStats := make(map[string]WordStats, len(safeWordCounts)+len(scamWordCounts))
+	seen := make(map[string]bool, len(safeWordCounts)+len(scamWordCounts))
+	for w := range safeWordCounts {
+		seen[w] = true
+	}
+	for w := range scamWordCounts {
+		seen[w] = true
+	}
+	for w := range seen {
+		safe := safeWordCounts[w]
+		scam := scamWordCounts[w]
+		wordStats[w] = WordStats{
+			SafeCount: safe,
+			ScamCount: scam,
+			SpamProb:  float64(scam+1) / float64(safe+scam+2), // Laplace-smoothed
+		}
+	}
+
+	return TrainingOutput{
+		TotalSafeEmails: safeCount,
+		TotalScamEmails: scamCount,
+		WordStats:       wordStats,
+	}
 }
 
 func analyzeDocument(index int, subject, body string, globalWordCounts, globalSentenceCounts map[string]int) Document {
@@ -711,21 +834,22 @@ func analyzeCSV(filePath string) {
 
 	fmt.Printf("Columns - Subject: %d, Body: %d, Label: %d\n", subjectIdx, bodyIdx, labelIdx)
 
-	// Accumulators
-	safeBodyWordCounts := make(map[string]int)
-	scamBodyWordCounts := make(map[string]int)
-	safeSubjectWordCounts := make(map[string]int)
-	scamSubjectWordCounts := make(map[string]int)
-
-	var safeWordSum, scamWordSum int
-	var safeSentSum, scamSentSum int
-	var safeShoutSum, scamShoutSum float64
-	var safeCount, scamCount int
-
 	htmlTagRegex := regexp.MustCompile(`<[^>]*>`)
 	sentSplit := regexp.MustCompile(`[.!?]+`)
 
+	numWorkers := workerCount(requestedWorkers)
+	rows := make(chan csvRow, numWorkers*4)
+
+	var mergedShard *csvShard
+	var workersDone sync.WaitGroup
+	workersDone.Add(1)
+	go func() {
+		defer workersDone.Done()
+		mergedShard = runCSVWorkers(rows, numWorkers, htmlTagRegex, sentSplit)
+	}()
+
 	count := 0
+	var safeFeatures, scamFeatures []EmailFeatures
 	for {
 		record, err := csvReader.Read()
 		if err == io.EOF {
@@ -744,112 +868,46 @@ func analyzeCSV(filePath string) {
 			fmt.Printf("Processed %d emails...\r", count)
 		}
 
-		// Determine Label
 		labelStr := strings.TrimSpace(record[labelIdx])
 		isScam := labelStr == "1" || strings.ToLower(labelStr) == "phish" || strings.ToLower(labelStr) == "spam"
 
-		// Get Content
 		subjectText := ""
 		if subjectIdx != -1 && subjectIdx < len(record) {
 			subjectText = record[subjectIdx]
 		}
-		bodyText := record[bodyIdx]
 
-		// 1. Body Analysis
-		cleanBody := htmlTagRegex.ReplaceAllString(bodyText, " ")
-		bodyLower := strings.ToLower(cleanBody)
-		bodyWords := wordRegex.FindAllString(bodyLower, -1)
-		uniqueBodyWords := make(map[string]bool)
-		for _, w := range bodyWords {
-			if len(w) < 3 || isNumeric(w) {
-				continue
-			}
-			if len(validEnglishWords) > 0 && !validEnglishWords[w] {
-				continue
-			}
-			if stopWords[w] {
-				continue
-			}
-			if phishingIrrelevant[w] {
-				continue
-			}
-			uniqueBodyWords[w] = true
-		}
-
-		// 2. Subject Analysis
-		subjLower := strings.ToLower(subjectText)
-		subjWords := wordRegex.FindAllString(subjLower, -1)
-		uniqueSubjWords := make(map[string]bool)
-		for _, w := range subjWords {
-			if len(w) < 2 || isNumeric(w) {
-				continue
-			} // Allow 2-letter words in subject (e.g. "re", "fw")
-			if stopWords[w] {
-				continue
-			}
-			if phishingIrrelevant[w] {
-				continue
-			}
-			uniqueSubjWords[w] = true
-		}
-
-		// 3. Update Global Stats
+		// Outlier detection needs feature vectors in memory, unlike the
+		// rest of this streaming path; outlierCSVCap bounds that per class
+		// so a very large CSV still runs in bounded memory.
+		cleanBody := htmlTagRegex.ReplaceAllString(record[bodyIdx], " ")
+		feat := extractFeatures(cleanBody, sentSplit)
 		if isScam {
-			scamCount++
-			// Body Stats
-			scamWordSum += len(bodyWords) // Rough estimate
-			scamShoutSum += calculateShoutingScore(cleanBody)
-
-			// Sentences (Body only)
-			sentences := sentSplit.Split(cleanBody, -1)
-			currSentCount := 0
-			for _, s := range sentences {
-				if len(strings.TrimSpace(s)) > 10 {
-					currSentCount++
-				}
-			}
-			if currSentCount == 0 {
-				currSentCount = 1
-			}
-			scamSentSum += len(bodyWords) / currSentCount
-
-			// Word Frequencies
-			for w := range uniqueBodyWords {
-				scamBodyWordCounts[w]++
-			}
-			for w := range uniqueSubjWords {
-				scamSubjectWordCounts[w]++
+			if len(scamFeatures) < outlierCSVCap {
+				scamFeatures = append(scamFeatures, feat)
 			}
+		} else if len(safeFeatures) < outlierCSVCap {
+			safeFeatures = append(safeFeatures, feat)
+		}
 
-		} else {
-			safeCount++
-			// Body Stats
-			safeWordSum += len(bodyWords)
-			safeShoutSum += calculateShoutingScore(cleanBody)
-
-			// Sentences
-			sentences := sentSplit.Split(cleanBody, -1)
-			currSentCount := 0
-			for _, s := range sentences {
-				if len(strings.TrimSpace(s)) > 10 {
-					currSentCount++
-				}
-			}
-			if currSentCount == 0 {
-				currSentCount = 1
-			}
-			safeSentSum += len(bodyWords) / currSentCount
+		rows <- csvRow{subject: subjectText, body: record[bodyIdx], isScam: isScam}
+	}
+	close(rows)
+	workersDone.Wait()
 
-			// Word Frequencies
-			for w := range uniqueBodyWords {
-				safeBodyWordCounts[w]++
-			}
-			for w := range uniqueSubjWords {
-				safeSubjectWordCounts[w]++
-			}
-		}
+	outlierReport := OutlierReport{
+		TopScamOutliers: topOutliers(scamFeatures, outlierTopM, rand.New(rand.NewSource(subsampleSeed+1_000_000))),
+		TopSafeOutliers: topOutliers(safeFeatures, outlierTopM, rand.New(rand.NewSource(subsampleSeed+2_000_000))),
 	}
 
+	safeBodyWordCounts := mergedShard.safeBodyWordCounts
+	scamBodyWordCounts := mergedShard.scamBodyWordCounts
+	safeSubjectWordCounts := mergedShard.safeSubjectWordCounts
+	scamSubjectWordCounts := mergedShard.scamSubjectWordCounts
+	safeWordSum, scamWordSum := mergedShard.safeWordSum, mergedShard.scamWordSum
+	safeSentSum, scamSentSum := mergedShard.safeSentSum, mergedShard.scamSentSum
+	safeShoutSum, scamShoutSum := mergedShard.safeShoutSum, mergedShard.scamShoutSum
+	safeCount, scamCount := mergedShard.safeCount, mergedShard.scamCount
+
 	fmt.Printf("\nTotal Emails: %d (Safe: %d, Scam: %d)\n", count, safeCount, scamCount)
 	fmt.Println("Generating linguistic report...")
 
@@ -883,7 +941,18 @@ func analyzeCSV(filePath string) {
 		return float64(n) / float64(d)
 	}
 
+	combinedSafeWordCounts := mergeWordCounts(safeBodyWordCounts, safeSubjectWordCounts)
+	combinedScamWordCounts := mergeWordCounts(scamBodyWordCounts, scamSubjectWordCounts)
+	topScamIndicators, topSafeIndicators := topIndicators(combinedSafeWordCounts, combinedScamWordCounts, defaultIndicatorMinCount, indicatorTopN)
+	topScamBigrams, topSafeBigrams := topBigrams(mergedShard.safeBodyBigrams, mergedShard.scamBodyBigrams, defaultIndicatorMinCount, bigramTopN)
+	writeBigramModels(mergedShard.safeBodyBigrams, mergedShard.scamBodyBigrams)
+
 	report := LinguisticReport{
+		TopScamIndicators: topScamIndicators,
+		TopSafeIndicators: topSafeIndicators,
+		TopScamBigrams:    topScamBigrams,
+		TopSafeBigrams:    topSafeBigrams,
+		Outliers:          outlierReport,
 		SafeStats: LinguisticStats{
 			TotalEmails:       safeCount,
 			AvgWordCount:      safeDiv(safeWordSum, safeCount),
@@ -914,4 +983,20 @@ func analyzeCSV(filePath string) {
 		log.Fatal(err)
 	}
 	fmt.Println("Done! Linguistic analysis written to linguistic_stats.json")
+
+	writeWordStats(combinedSafeWordCounts, combinedScamWordCounts, safeCount, scamCount)
+}
+
+// mergeWordCounts sums two per-word document-count maps, used to fold
+// subject word frequencies into the same vocabulary as body words before
+// persisting a word_stats.json model.
+func mergeWordCounts(a, b map[string]int) map[string]int {
+	merged := make(map[string]int, len(a)+len(b))
+	for w, c := range a {
+		merged[w] += c
+	}
+	for w, c := range b {
+		merged[w] += c
+	}
+	return merged
 }
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+)
+
+// defaultSketchWidth/defaultSketchDepth size the Count-Min Sketch used in
+// -sketch mode. With these defaults epsilon = e/width ~= 0.13% of the total
+// count and delta = e^-depth ~= 0.7%, i.e. any estimate is within epsilon*N
+// of the true count with better than 99% confidence.
+const defaultSketchWidth = 2048
+const defaultSketchDepth = 5
+
+// defaultSketchCapacity bounds the Space-Saving heavy-hitters structure,
+// sized as topN*c (100 reported words * 10) so getTopWords keeps returning
+// accurate leaders even though the underlying frequency table is lossy.
+const defaultSketchCapacity = 1000
+
+// CountMinSketch is a fixed-memory approximate frequency table: Add never
+// undercounts, Estimate may overcount by at most epsilon*N with probability
+// 1-delta (see ErrorBound), where epsilon and delta are set by width/depth.
+type CountMinSketch struct {
+	width uint
+	depth uint
+	table [][]int64
+	seeds []uint64
+}
+
+// NewCountMinSketch returns a sketch with depth independent rows of width
+// counters each, seeded deterministically so Add/Estimate agree across
+// sketches built with the same dimensions (required for Merge).
+func NewCountMinSketch(width, depth uint) *CountMinSketch {
+	if width == 0 {
+		width = defaultSketchWidth
+	}
+	if depth == 0 {
+		depth = defaultSketchDepth
+	}
+	table := make([][]int64, depth)
+	seeds := make([]uint64, depth)
+	for i := range table {
+		table[i] = make([]int64, width)
+		seeds[i] = uint64(i)*0x9E3779B97F4A7C15 + 1
+	}
+	return &CountMinSketch{width: width, depth: depth, table: table, seeds: seeds}
+}
+
+func (c *CountMinSketch) rowIndex(row int, item string) uint {
+	h := fnv.New64a()
+	var seedBuf [8]byte
+	binary.LittleEndian.PutUint64(seedBuf[:], c.seeds[row])
+	h.Write(seedBuf[:])
+	h.Write([]byte(item))
+	return uint(h.Sum64() % uint64(c.width))
+}
+
+// Add records count additional occurrences of item.
+func (c *CountMinSketch) Add(item string, count int) {
+	for row := range c.table {
+		idx := c.rowIndex(row, item)
+		c.table[row][idx] += int64(count)
+	}
+}
+
+// Estimate returns item's approximate occurrence count: the minimum across
+// every row, which cancels out hash collisions that would otherwise only
+// ever inflate the count.
+func (c *CountMinSketch) Estimate(item string) int64 {
+	min := int64(math.MaxInt64)
+	for row := range c.table {
+		idx := c.rowIndex(row, item)
+		if v := c.table[row][idx]; v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// Merge folds other's counters into c, requiring identical dimensions.
+func (c *CountMinSketch) Merge(other *CountMinSketch) error {
+	if other == nil {
+		return nil
+	}
+	if c.width != other.width || c.depth != other.depth {
+		return fmt.Errorf("sketch: cannot merge Count-Min Sketches of shape %dx%d and %dx%d", c.depth, c.width, other.depth, other.width)
+	}
+	for row := range c.table {
+		for i, v := range other.table[row] {
+			c.table[row][i] += v
+		}
+	}
+	return nil
+}
+
+// ErrorBound returns (epsilon, delta): Estimate overcounts by at most
+// epsilon*totalCount with probability at least 1-delta.
+func (c *CountMinSketch) ErrorBound() (epsilon, delta float64) {
+	epsilon = math.E / float64(c.width)
+	delta = math.Exp(-float64(c.depth))
+	return epsilon, delta
+}
+
+// hhEntry is one tracked item in a HeavyHitters structure, along with the
+// Space-Saving error bound on its count (the count the evicted item held
+// when this item took its slot).
+type hhEntry struct {
+	word  string
+	count int64
+	error int64
+}
+
+// HeavyHitters implements the Space-Saving algorithm (a refinement of
+// Misra-Gries): it tracks at most capacity items, always keeping the
+// highest-frequency ones, so the exact top-K words survive even when the
+// full vocabulary is too large to count in an unbounded map.
+type HeavyHitters struct {
+	capacity int
+	entries  map[string]*hhEntry
+}
+
+// NewHeavyHitters returns a HeavyHitters structure tracking at most
+// capacity distinct items.
+func NewHeavyHitters(capacity int) *HeavyHitters {
+	if capacity <= 0 {
+		capacity = defaultSketchCapacity
+	}
+	return &HeavyHitters{capacity: capacity, entries: make(map[string]*hhEntry, capacity)}
+}
+
+// Add records one occurrence of item.
+func (hh *HeavyHitters) Add(item string) {
+	hh.addCount(item, 1)
+}
+
+// addCount records count occurrences of item, evicting the current
+// lowest-count entry when at capacity (the Space-Saving replacement rule).
+func (hh *HeavyHitters) addCount(item string, count int64) {
+	if e, ok := hh.entries[item]; ok {
+		e.count += count
+		return
+	}
+	if len(hh.entries) < hh.capacity {
+		hh.entries[item] = &hhEntry{word: item, count: count}
+		return
+	}
+
+	var min *hhEntry
+	for _, e := range hh.entries {
+		if min == nil || e.count < min.count {
+			min = e
+		}
+	}
+	delete(hh.entries, min.word)
+	hh.entries[item] = &hhEntry{word: item, count: min.count + count, error: min.count}
+}
+
+// Merge folds other's tracked counts into hh.
+func (hh *HeavyHitters) Merge(other *HeavyHitters) {
+	if other == nil {
+		return
+	}
+	for _, e := range other.entries {
+		hh.addCount(e.word, e.count)
+	}
+}
+
+// HHCount is one item surfaced by HeavyHitters.Top, with the Space-Saving
+// error bound on its count (the true count is between Count-Error and
+// Count).
+type HHCount struct {
+	Word  string
+	Count int64
+	Error int64
+}
+
+// Top returns up to n tracked items sorted by descending count.
+func (hh *HeavyHitters) Top(n int) []HHCount {
+	list := make([]HHCount, 0, len(hh.entries))
+	for _, e := range hh.entries {
+		list = append(list, HHCount{Word: e.word, Count: e.count, Error: e.error})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Count > list[j].Count })
+	if n < len(list) {
+		list = list[:n]
+	}
+	return list
+}
+
+// SketchInfo records the -sketch mode parameters and their resulting error
+// bounds, written into LinguisticReport so a reader can judge how much to
+// trust the (lossy) word counts.
+type SketchInfo struct {
+	Width               uint    `json:"count_min_width"`
+	Depth               uint    `json:"count_min_depth"`
+	HeavyHitterCapacity int     `json:"heavy_hitter_capacity"`
+	Epsilon             float64 `json:"epsilon"`
+	Delta               float64 `json:"delta"`
+}
+
+// buildSketchInfo summarizes cms's dimensions and their implied error bound.
+func buildSketchInfo(cms *CountMinSketch, heavyHitterCapacity int) *SketchInfo {
+	epsilon, delta := cms.ErrorBound()
+	return &SketchInfo{
+		Width:               cms.width,
+		Depth:               cms.depth,
+		HeavyHitterCapacity: heavyHitterCapacity,
+		Epsilon:             epsilon,
+		Delta:               delta,
+	}
+}
+
+// materializeFromHeavyHitters builds a small map[string]int for the words
+// HeavyHitters actually kept, using the Count-Min Sketch's (more accurate,
+// monotonically non-decreasing) estimate rather than the Space-Saving
+// count itself. This is the bridge back to the exact-map-shaped functions
+// (getTopWords, topIndicators, topBigrams) so they work unmodified on a
+// bounded-size approximation instead of the full vocabulary.
+func materializeFromHeavyHitters(hh *HeavyHitters, cms *CountMinSketch, capacity int) map[string]int {
+	top := hh.Top(capacity)
+	out := make(map[string]int, len(top))
+	for _, e := range top {
+		out[e.Word] = int(cms.Estimate(e.Word))
+	}
+	return out
+}
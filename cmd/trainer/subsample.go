@@ -0,0 +1,60 @@
+package main
+
+import "math/rand"
+
+// defaultSubsampleLimit is the default token-count threshold T above which
+// a document's tokens are replaced with a uniform random sample, resisting
+// padding attacks where a scammer pastes large blocks of benign text to
+// dilute the handful of scam tokens a frequency-based classifier relies on.
+const defaultSubsampleLimit = 200
+
+// defaultSubsampleSeed seeds the sampling RNG when -seed isn't given, so a
+// run is reproducible unless the caller asks for a different seed.
+const defaultSubsampleSeed = 42
+
+// subsampleThreshold and subsampleSeed are set from the -subsample-limit/
+// -seed flags in main(). subsampleThreshold <= 0 disables subsampling.
+var subsampleThreshold int
+var subsampleSeed int64
+
+// subsampleTokens resists padding attacks: once tokens exceeds limit, it
+// returns a uniform random sample of limit token occurrences drawn without
+// replacement instead of the full slice.
+//
+// Invariant: callers must apply this after tokenization/normalization but
+// before building a unique-word set (uniqueWordsInDoc / uniqueBodyWords)
+// from the result. Sampling the raw token stream first, then deduplicating
+// the sample, is what actually bounds a document's contribution to the
+// per-class word counts - deduplicating first and sampling the unique set
+// instead would let an attacker inflate coverage by using more distinct
+// padding words rather than more tokens.
+func subsampleTokens(tokens []string, limit int, rng *rand.Rand) []string {
+	if limit <= 0 || len(tokens) <= limit {
+		return tokens
+	}
+	sample := append([]string(nil), tokens...)
+	rng.Shuffle(len(sample), func(i, j int) { sample[i], sample[j] = sample[j], sample[i] })
+	return sample[:limit]
+}
+
+// newSampleRNG returns a *rand.Rand seeded deterministically from
+// subsampleSeed and shardIndex, so concurrent workers don't share (and
+// contend on) a single math/rand source while runs stay reproducible for a
+// given -seed.
+func newSampleRNG(shardIndex int) *rand.Rand {
+	return rand.New(rand.NewSource(subsampleSeed + int64(shardIndex)))
+}
+
+// classifyRNG backs classifySampleRNG. classify runs single-threaded (the
+// -classify CLI path and evaluate.go's per-fold loop both call it
+// sequentially), so one shared, lazily-seeded source is enough here.
+var classifyRNG *rand.Rand
+
+// classifySampleRNG returns the classifier path's sampling RNG, seeded from
+// subsampleSeed on first use.
+func classifySampleRNG() *rand.Rand {
+	if classifyRNG == nil {
+		classifyRNG = rand.New(rand.NewSource(subsampleSeed))
+	}
+	return classifyRNG
+}
@@ -0,0 +1,204 @@
+// Package dmarc parses DMARC DNS TXT records and RFC 8601
+// Authentication-Results headers, replacing substring-matching heuristics
+// with structured, policy-aware data.
+package dmarc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Policy is the parsed set of tag-value pairs from a "v=DMARC1" TXT record.
+type Policy struct {
+	Version         string   // v=
+	Policy          string   // p= (none/quarantine/reject)
+	SubdomainPolicy string   // sp= (defaults to Policy if absent)
+	Percent         int      // pct= (defaults to 100)
+	DKIMAlignment   string   // adkim= (r=relaxed, s=strict)
+	SPFAlignment    string   // aspf= (r=relaxed, s=strict)
+	ReportURIs      []string // rua=
+	ForensicURIs    []string // ruf=
+	FailureOptions  string   // fo=
+}
+
+// AlignmentStrict reports whether both DKIM and SPF alignment are set to
+// strict ("s") mode. Relaxed ("r") is the default per RFC 7489.
+func (p Policy) AlignmentStrict() bool {
+	return p.DKIMAlignment == "s" && p.SPFAlignment == "s"
+}
+
+// ParsePolicy parses a DMARC TXT record body (e.g. "v=DMARC1; p=reject; pct=100")
+// into a Policy. It returns an error if the record is not a DMARC record or
+// is missing the required "p=" tag.
+func ParsePolicy(txt string) (Policy, error) {
+	tags := parseTagValues(txt)
+
+	if !strings.EqualFold(tags["v"], "DMARC1") {
+		return Policy{}, fmt.Errorf("dmarc: not a DMARC1 record: %q", txt)
+	}
+
+	p := Policy{
+		Version:         tags["v"],
+		Policy:          strings.ToLower(tags["p"]),
+		SubdomainPolicy: strings.ToLower(tags["sp"]),
+		Percent:         100,
+		DKIMAlignment:   "r",
+		SPFAlignment:    "r",
+		FailureOptions:  tags["fo"],
+	}
+	if p.Policy == "" {
+		return Policy{}, fmt.Errorf("dmarc: record missing required p= tag: %q", txt)
+	}
+	if p.SubdomainPolicy == "" {
+		p.SubdomainPolicy = p.Policy
+	}
+	if pct, ok := tags["pct"]; ok {
+		if n, err := strconv.Atoi(pct); err == nil {
+			p.Percent = n
+		}
+	}
+	if v, ok := tags["adkim"]; ok {
+		p.DKIMAlignment = strings.ToLower(v)
+	}
+	if v, ok := tags["aspf"]; ok {
+		p.SPFAlignment = strings.ToLower(v)
+	}
+	if rua, ok := tags["rua"]; ok {
+		p.ReportURIs = splitURIList(rua)
+	}
+	if ruf, ok := tags["ruf"]; ok {
+		p.ForensicURIs = splitURIList(ruf)
+	}
+
+	return p, nil
+}
+
+// parseTagValues splits a "tag1=value1; tag2=value2" record into a map,
+// lower-casing tag names (tag names are case-insensitive per RFC 7489,
+// values are not).
+func parseTagValues(txt string) map[string]string {
+	tags := make(map[string]string)
+	for _, part := range strings.Split(txt, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		tags[key] = strings.TrimSpace(kv[1])
+	}
+	return tags
+}
+
+// splitURIList splits a comma-separated rua=/ruf= tag value into individual
+// report URIs (each typically "mailto:...", optionally with "!size" suffix).
+func splitURIList(v string) []string {
+	parts := strings.Split(v, ",")
+	uris := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			uris = append(uris, p)
+		}
+	}
+	return uris
+}
+
+// MethodResult is a single mechanism's outcome from an Authentication-Results
+// header, e.g. "spf=pass smtp.mailfrom=example.com".
+type MethodResult struct {
+	Mechanism  string // spf, dkim, dmarc, arc
+	Result     string // pass, fail, neutral, softfail, none, temperror, permerror
+	Identifier string // the "smtp.mailfrom=" / "header.d=" / "header.i=" property, if present
+	Comment    string // a reason="..." comment, if present
+}
+
+// ParseAuthResults tokenizes an Authentication-Results header per RFC 8601
+// into one MethodResult per resinfo clause ("method=result [properties]"),
+// rather than relying on substring checks that get confused by comments and
+// multi-mechanism results.
+func ParseAuthResults(header string) []MethodResult {
+	// Authentication-Results begins with "authserv-id [version]"; drop it by
+	// finding the first "method=" token.
+	fields := tokenizeAuthResults(header)
+
+	var results []MethodResult
+	var current *MethodResult
+	for _, f := range fields {
+		if mech, result, ok := splitMethodResult(f); ok {
+			if current != nil {
+				results = append(results, *current)
+			}
+			current = &MethodResult{Mechanism: strings.ToLower(mech), Result: strings.ToLower(result)}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if strings.HasPrefix(f, "reason=") {
+			current.Comment = unquote(strings.TrimPrefix(f, "reason="))
+			continue
+		}
+		if eq := strings.Index(f, "="); eq != -1 {
+			prop := f[:eq]
+			// Identity properties look like "smtp.mailfrom", "header.d", "header.i".
+			if strings.Contains(prop, ".") {
+				current.Identifier = f[eq+1:]
+			}
+		}
+	}
+	if current != nil {
+		results = append(results, *current)
+	}
+	return results
+}
+
+// tokenizeAuthResults splits the header into whitespace-separated fields
+// while keeping quoted comments (for reason="...") intact.
+func tokenizeAuthResults(header string) []string {
+	var fields []string
+	var sb strings.Builder
+	inQuotes := false
+	for _, r := range header {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			sb.WriteRune(r)
+		case (r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == ';') && !inQuotes:
+			if sb.Len() > 0 {
+				fields = append(fields, sb.String())
+				sb.Reset()
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() > 0 {
+		fields = append(fields, sb.String())
+	}
+	return fields
+}
+
+// splitMethodResult recognizes a "method=result" token such as "dkim=pass",
+// as opposed to a property token such as "header.i=example.com".
+func splitMethodResult(field string) (mechanism, result string, ok bool) {
+	eq := strings.Index(field, "=")
+	if eq == -1 {
+		return "", "", false
+	}
+	mech := field[:eq]
+	val := field[eq+1:]
+	switch strings.ToLower(mech) {
+	case "spf", "dkim", "dmarc", "arc":
+		return mech, val, true
+	}
+	return "", "", false
+}
+
+func unquote(s string) string {
+	return strings.Trim(s, `"`)
+}
@@ -0,0 +1,219 @@
+// Package emlparse reads raw .eml files into a typed Message: RFC 5322
+// header unfolding (via net/mail), RFC 2047 encoded-word decoding of the
+// headers phishing analysis actually cares about (via headerdecode's
+// LenientDecoder, so malformed phishing-kit headers still decode),
+// address-list parsing into display-name + addr-spec pairs, and MIME body
+// decoding (via bodydecode) so Body holds readable text instead of raw
+// MIME structure.
+package emlparse
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/mail"
+	"os"
+	"strings"
+
+	"github.com/jonasboos/phishing-live/bodydecode"
+	"github.com/jonasboos/phishing-live/headerdecode"
+	"github.com/jonasboos/phishing-live/homograph"
+)
+
+// Address is one parsed address-list entry: a decoded display name next to
+// its canonical addr-spec.
+type Address struct {
+	Name string `json:"name,omitempty"`
+	Addr string `json:"addr"`
+}
+
+// Header is one header line as it appeared in the message (already
+// unfolded across continuation lines by net/mail) alongside its RFC
+// 2047-decoded form.
+type Header struct {
+	Name    string `json:"name"`
+	Raw     string `json:"raw"`
+	Decoded string `json:"decoded"`
+}
+
+// Message is a parsed .eml file. Headers preserves every header line in
+// both forms; the named fields below hold the structured headers phishing
+// analysis inspects most often, already decoded and (for address-bearing
+// headers) split into Address pairs. RepairEvents records every malformed
+// encoded-word LenientDecoder had to patch while decoding any header, so a
+// malformed header can itself be scored as a phishing signal.
+type Message struct {
+	Headers      []Header                   `json:"headers"`
+	RepairEvents []headerdecode.RepairEvent `json:"repair_events,omitempty"`
+
+	Subject         string    `json:"subject"`
+	From            []Address `json:"from,omitempty"`
+	To              []Address `json:"to,omitempty"`
+	ReplyTo         []Address `json:"reply_to,omitempty"`
+	Sender          []Address `json:"sender,omitempty"`
+	ListUnsubscribe string    `json:"list_unsubscribe,omitempty"`
+	Filename        string    `json:"filename,omitempty"` // from Content-Disposition, if present
+
+	// Body is the message's plain-text content, decoded per-part via
+	// bodydecode: the first text/plain leaf if one exists, otherwise the
+	// first leaf of any type. Parts holds the full decoded MIME tree
+	// Body was flattened from, for callers that need individual parts
+	// (e.g. an HTML leaf's own markup) rather than the flattened text.
+	Body  string                 `json:"body"`
+	Parts bodydecode.DecodedPart `json:"parts"`
+
+	// HomographFindings flags mixed-script, confusable, or invisible-
+	// codepoint domain labels across From/Reply-To/Sender - the
+	// identities a phishing kit spoofs - that LenientDecoder would
+	// otherwise happily decode and print without flagging.
+	HomographFindings []homograph.Finding `json:"homograph_findings,omitempty"`
+}
+
+// ParseFile reads and parses the .eml file at path.
+func ParseFile(path string) (*Message, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a .eml message from r.
+func Parse(r io.Reader) (*Message, error) {
+	raw, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("emlparse: %w", err)
+	}
+
+	msg := &Message{}
+	dec := headerdecode.NewLenientDecoder()
+
+	// decodeHeader decodes raw via LenientDecoder, recording any repair it
+	// had to make on msg.RepairEvents so malformed-header phishing kit
+	// output stays decodable instead of falling back to raw text.
+	decodeHeader := func(raw string) string {
+		if raw == "" {
+			return ""
+		}
+		decoded, events := dec.DecodeHeader(raw)
+		msg.RepairEvents = append(msg.RepairEvents, events...)
+		return decoded
+	}
+
+	for name, values := range raw.Header {
+		for _, v := range values {
+			msg.Headers = append(msg.Headers, Header{Name: name, Raw: v, Decoded: decodeHeader(v)})
+		}
+	}
+
+	msg.Subject = decodeHeader(raw.Header.Get("Subject"))
+	msg.From = parseAddressList(raw.Header.Get("From"), decodeHeader)
+	msg.To = parseAddressList(raw.Header.Get("To"), decodeHeader)
+	msg.ReplyTo = parseAddressList(raw.Header.Get("Reply-To"), decodeHeader)
+	msg.Sender = parseAddressList(raw.Header.Get("Sender"), decodeHeader)
+	msg.ListUnsubscribe = decodeHeader(raw.Header.Get("List-Unsubscribe"))
+	msg.Filename = dispositionFilename(raw.Header.Get("Content-Disposition"))
+	msg.HomographFindings = scanAddressDomains(msg.From, msg.ReplyTo, msg.Sender)
+
+	contentType := raw.Header.Get("Content-Type")
+	if contentType == "" {
+		// RFC 2045 §5.2 default for a message with no Content-Type.
+		contentType = "text/plain; charset=us-ascii"
+	}
+	decodedBody, err := bodydecode.Decode(contentType, raw.Body)
+	if err != nil {
+		return nil, fmt.Errorf("emlparse: decoding body: %w", err)
+	}
+	msg.Parts = decodedBody
+	msg.Body = flattenBody(decodedBody)
+
+	return msg, nil
+}
+
+// flattenBody returns the single most relevant leaf of a decoded MIME part
+// tree: the first text/plain leaf found (depth-first), or failing that the
+// first leaf of any type.
+func flattenBody(part bodydecode.DecodedPart) string {
+	if body, ok := findLeaf(part, "text/plain"); ok {
+		return body
+	}
+	body, _ := findLeaf(part, "")
+	return body
+}
+
+// findLeaf searches part depth-first for a leaf (no sub-Parts) whose
+// MediaType matches wantType, or any leaf at all when wantType is empty.
+func findLeaf(part bodydecode.DecodedPart, wantType string) (string, bool) {
+	if len(part.Parts) == 0 {
+		if wantType == "" || part.MediaType == wantType {
+			return part.UTF8Body, true
+		}
+		return "", false
+	}
+	for _, p := range part.Parts {
+		if body, ok := findLeaf(p, wantType); ok {
+			return body, true
+		}
+	}
+	return "", false
+}
+
+// scanAddressDomains runs homograph.ScanDomain over the distinct domains in
+// every address list passed in (From, Reply-To, Sender), so a spoofed
+// sender identity using mixed scripts, confusable lookalikes, or invisible
+// codepoints is flagged regardless of which address-bearing header it
+// appears in.
+func scanAddressDomains(lists ...[]Address) []homograph.Finding {
+	var findings []homograph.Finding
+	seen := make(map[string]bool)
+	for _, addrs := range lists {
+		for _, a := range addrs {
+			_, domain, ok := strings.Cut(a.Addr, "@")
+			if !ok || domain == "" || seen[domain] {
+				continue
+			}
+			seen[domain] = true
+			findings = append(findings, homograph.ScanDomain(domain)...)
+		}
+	}
+	return findings
+}
+
+// parseAddressList decodes raw's RFC 2047 encoded-words first (via
+// decodeHeader), then hands the result to net/mail's address-list parser.
+// Encoded-words can't themselves contain the commas/angle-brackets address
+// syntax depends on, so decoding first is safe and lets
+// mail.ParseAddressList do the rest: splitting on commas, matching quoted
+// display names, and (per RFC 2047 §6.2) treating whitespace-separated
+// adjacent encoded-words as a single run since LenientDecoder's underlying
+// mime.WordDecoder already collapses it.
+func parseAddressList(raw string, decodeHeader func(string) string) []Address {
+	if raw == "" {
+		return nil
+	}
+	decoded := decodeHeader(raw)
+	parsed, err := mail.ParseAddressList(decoded)
+	if err != nil {
+		return nil
+	}
+	addrs := make([]Address, len(parsed))
+	for i, a := range parsed {
+		addrs[i] = Address{Name: a.Name, Addr: a.Address}
+	}
+	return addrs
+}
+
+// dispositionFilename extracts the filename parameter from a
+// Content-Disposition header, relying on mime.ParseMediaType to reassemble
+// RFC 2231 filename*0/filename*1 continuations.
+func dispositionFilename(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(raw)
+	if err != nil {
+		return ""
+	}
+	return params["filename"]
+}
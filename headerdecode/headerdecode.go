@@ -0,0 +1,89 @@
+// Package headerdecode decodes RFC 2047 encoded-word email headers,
+// extending Go's stdlib mime.WordDecoder with the legacy charsets phishing
+// kits still emit. The stdlib decoder only understands utf-8/us-ascii
+// natively; a Subject line declared "=?windows-1251?B?...?=" or
+// "=?gb2312?Q?...?=" otherwise fails outright.
+package headerdecode
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// charsets maps the lowercase MIME charset names phishing samples actually
+// declare to their golang.org/x/text/encoding transformer.
+var charsets = map[string]encoding.Encoding{
+	"iso-8859-1":   charmap.ISO8859_1,
+	"iso-8859-2":   charmap.ISO8859_2,
+	"iso-8859-15":  charmap.ISO8859_15,
+	"windows-1250": charmap.Windows1250,
+	"windows-1251": charmap.Windows1251,
+	"windows-1252": charmap.Windows1252,
+	"windows-1256": charmap.Windows1256,
+	"koi8-r":       charmap.KOI8R,
+	"gb2312":       simplifiedchinese.GBK,
+	"gbk":          simplifiedchinese.GBK,
+	"big5":         traditionalchinese.Big5,
+	"shift_jis":    japanese.ShiftJIS,
+	"euc-kr":       korean.EUCKR,
+}
+
+// Mode selects how a *mime.WordDecoder built by NewDecoder handles a
+// charset outside the table above.
+type Mode int
+
+const (
+	// Strict returns an error for an unrecognized charset.
+	Strict Mode = iota
+	// Lenient decodes an unrecognized charset as Latin-1 - a byte-for-byte
+	// best guess - rather than failing outright, since phishing kits
+	// frequently misdeclare or invent charset names.
+	Lenient
+)
+
+// NewDecoder returns a *mime.WordDecoder whose CharsetReader resolves the
+// charsets table, falling back per mode when a charset isn't recognized.
+func NewDecoder(mode Mode) *mime.WordDecoder {
+	return &mime.WordDecoder{
+		CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+			if enc, ok := charsets[strings.ToLower(charset)]; ok {
+				return enc.NewDecoder().Reader(input), nil
+			}
+			if mode == Lenient {
+				return charmap.ISO8859_1.NewDecoder().Reader(input), nil
+			}
+			return nil, fmt.Errorf("headerdecode: unsupported charset %q", charset)
+		},
+	}
+}
+
+// DecodeHeader decodes an RFC 2047 encoded-word header in Lenient mode, so
+// an unrecognized charset degrades to a best-effort Latin-1 pass instead of
+// returning an error.
+func DecodeHeader(header string) (string, error) {
+	return NewDecoder(Lenient).DecodeHeader(header)
+}
+
+// DecodeHeaderStrict is DecodeHeader but returns an error for any charset
+// not in the charsets table.
+func DecodeHeaderStrict(header string) (string, error) {
+	return NewDecoder(Strict).DecodeHeader(header)
+}
+
+// Charset returns the golang.org/x/text/encoding transformer for the given
+// MIME charset name, so other packages (e.g. bodydecode, decoding a body
+// part's text per its declared charset) apply the exact same charset
+// resolution DecodeHeader uses for headers.
+func Charset(name string) (encoding.Encoding, bool) {
+	enc, ok := charsets[strings.ToLower(name)]
+	return enc, ok
+}
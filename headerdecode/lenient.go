@@ -0,0 +1,98 @@
+package headerdecode
+
+import (
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// RepairEvent describes one malformed encoded-word fragment LenientDecoder
+// patched before it could be decoded.
+type RepairEvent struct {
+	Fragment string `json:"fragment"` // the original, as found in the header
+	Repair   string `json:"repair"`   // the sanitized fragment that decoded successfully
+}
+
+// encodedWordRegex matches a best-effort "=?charset?enc?text?=" fragment,
+// tolerating a missing or partial trailing "?=" - phishing kits routinely
+// truncate it.
+var encodedWordRegex = regexp.MustCompile(`=\?([^?\s]+)\?([bBqQ])\?([^?]*)(\?=?)?`)
+
+// qEscapeRegex matches a Q-encoding "=XX" hex escape, or a shorter/bare "="
+// where the hex digits are missing or truncated.
+var qEscapeRegex = regexp.MustCompile(`=[0-9A-Fa-f]{0,2}`)
+
+// LenientDecoder decodes RFC 2047 encoded-word headers like
+// *mime.WordDecoder, but on failure repairs the malformations phishing
+// kits routinely produce - unquoted '"' inside Q-encoded text, a missing
+// trailing "?=", whitespace inside the encoded-text, bad "=XX" hex
+// escapes, and truncated Base64 padding - token by token instead of
+// giving up on the whole header.
+type LenientDecoder struct {
+	dec *mime.WordDecoder
+}
+
+// NewLenientDecoder returns a LenientDecoder built on NewDecoder(Lenient).
+func NewLenientDecoder() *LenientDecoder {
+	return &LenientDecoder{dec: NewDecoder(Lenient)}
+}
+
+// DecodeHeader decodes header, trying the underlying stdlib-compatible
+// decoder on the whole string first and falling back to per-fragment
+// repair when that either errors or leaves an undecoded "=?" marker behind
+// - the stdlib decoder doesn't error on a malformed encoded-word it
+// doesn't recognize, it just passes it through as literal text. It returns
+// the decoded string plus a RepairEvent for every fragment that needed
+// patching.
+func (d *LenientDecoder) DecodeHeader(header string) (string, []RepairEvent) {
+	if decoded, err := d.dec.DecodeHeader(header); err == nil && !strings.Contains(decoded, "=?") {
+		return decoded, nil
+	}
+
+	var events []RepairEvent
+	decoded := encodedWordRegex.ReplaceAllStringFunc(header, func(fragment string) string {
+		repaired := repairFragment(fragment)
+		result, err := d.dec.DecodeHeader(repaired)
+		if err != nil {
+			// Still undecodable even after repair - leave the original
+			// fragment in place rather than losing data.
+			return fragment
+		}
+		if repaired != fragment {
+			events = append(events, RepairEvent{Fragment: fragment, Repair: repaired})
+		}
+		return result
+	})
+	return decoded, events
+}
+
+// repairFragment sanitizes one "=?charset?enc?text?=" fragment: stray
+// quotes and whitespace are stripped from text, Base64 text is padded to a
+// multiple of 4, Q-encoded text has its invalid/truncated "=XX" escapes
+// replaced with U+FFFD, and a missing trailing "?=" is restored.
+func repairFragment(fragment string) string {
+	m := encodedWordRegex.FindStringSubmatch(fragment)
+	if m == nil {
+		return fragment
+	}
+	charset, enc, text := m[1], strings.ToUpper(m[2]), m[3]
+
+	text = strings.ReplaceAll(text, `"`, "")
+	text = strings.ReplaceAll(text, " ", "")
+
+	if enc == "Q" {
+		text = qEscapeRegex.ReplaceAllStringFunc(text, func(esc string) string {
+			if len(esc) == 3 {
+				return esc // valid "=XX" - let the underlying decoder handle it
+			}
+			return "�"
+		})
+	} else {
+		if rem := len(text) % 4; rem != 0 {
+			text += strings.Repeat("=", 4-rem)
+		}
+	}
+
+	return fmt.Sprintf("=?%s?%s?%s?=", charset, enc, text)
+}
@@ -0,0 +1,160 @@
+// Package homoglyph detects internationalized-domain spoofing of a trusted
+// brand: Punycode labels, visually-confusable Unicode codepoints (a small
+// hand-picked Cyrillic/Greek/Latin table rather than the full TR39
+// confusables dataset), and near-miss typos on the eTLD+1.
+package homoglyph
+
+import (
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// confusables maps visually-similar codepoints to the Latin letter they're
+// commonly used to impersonate. Covers the Cyrillic/Greek homoglyphs seen in
+// real phishing domains (a-z lookalikes), not the full TR39 table.
+var confusables = map[rune]rune{
+	'а': 'a', // Cyrillic а U+0430
+	'ь': 'b',
+	'с': 'c', // Cyrillic с U+0441
+	'е': 'e', // Cyrillic е U+0435
+	'ԍ': 'g',
+	'һ': 'h',
+	'і': 'i', // Cyrillic і U+0456
+	'ј': 'j', // Cyrillic ј U+0458
+	'к': 'k',
+	'м': 'm',
+	'н': 'h',
+	'о': 'o', // Cyrillic о U+043E
+	'р': 'p', // Cyrillic р U+0440
+	'ѕ': 's', // Cyrillic ѕ U+0455
+	'т': 't',
+	'у': 'y', // Cyrillic у U+0443
+	'х': 'x', // Cyrillic х U+0445
+	'ѡ': 'w',
+	'ɑ': 'a',
+	'α': 'a', // Greek alpha
+	'β': 'b', // Greek beta
+	'ο': 'o', // Greek omicron U+03BF
+	'ρ': 'p', // Greek rho
+	'ν': 'v', // Greek nu
+	'０': '0',
+	'１': '1',
+	'ⅼ': 'l',
+}
+
+// Skeleton folds confusable codepoints to the Latin letter they imitate, per
+// the approach (if not the exact table) of Unicode TR39: two strings that
+// collide to the same skeleton are visually indistinguishable at a glance.
+func Skeleton(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if folded, ok := confusables[r]; ok {
+			sb.WriteRune(folded)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// ConfusableFold reports the Latin letter r is a commonly-used stand-in
+// for, per the confusables table above, and whether r is in that table at
+// all.
+func ConfusableFold(r rune) (rune, bool) {
+	folded, ok := confusables[r]
+	return folded, ok
+}
+
+// HasPunycodeLabel reports whether domain contains an ACE-encoded
+// ("xn--") label, i.e. it was typed or registered using non-ASCII
+// characters converted to Punycode.
+func HasPunycodeLabel(domain string) bool {
+	for _, label := range strings.Split(domain, ".") {
+		if strings.HasPrefix(label, "xn--") {
+			return true
+		}
+	}
+	return false
+}
+
+// ToUnicode decodes any Punycode labels in domain back to their Unicode
+// form, so confusables can be matched against the characters a user would
+// actually see rendered.
+func ToUnicode(domain string) string {
+	u, err := idna.ToUnicode(domain)
+	if err != nil {
+		return domain
+	}
+	return u
+}
+
+// Match finds the trusted domain that domain most plausibly impersonates.
+// It returns the matched trusted domain and true when domain differs from
+// it but either (a) shares the same confusables skeleton, or (b) is within
+// Damerau-Levenshtein distance 2. An exact match to a trusted domain is not
+// a lookalike and returns ok=false.
+func Match(domain string, trusted []string) (matched string, ok bool) {
+	unicodeForm := ToUnicode(domain)
+	skeleton := Skeleton(unicodeForm)
+
+	for _, t := range trusted {
+		if domain == t || strings.HasSuffix(domain, "."+t) {
+			return "", false
+		}
+
+		if skeleton == Skeleton(t) {
+			return t, true
+		}
+		if damerauLevenshtein(skeleton, t) <= 2 {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// damerauLevenshtein computes the Damerau-Levenshtein edit distance
+// (insertions, deletions, substitutions, and adjacent transpositions)
+// between a and b.
+func damerauLevenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	la, lb := len(ra), len(rb)
+
+	d := make([][]int, la+1)
+	for i := range d {
+		d[i] = make([]int, lb+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= lb; j++ {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= la; i++ {
+		for j := 1; j <= lb; j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(
+				d[i-1][j]+1,
+				d[i][j-1]+1,
+				d[i-1][j-1]+cost,
+			)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+cost)
+			}
+		}
+	}
+	return d[la][lb]
+}
+
+func min3(a, b, c int) int {
+	return min(min(a, b), c)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
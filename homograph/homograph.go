@@ -0,0 +1,126 @@
+// Package homograph flags individual domain labels (from decoded From
+// headers or harvested URLs) that use mixed Unicode scripts, confusable
+// lookalike characters, or invisible codepoints - the building blocks of a
+// homograph attack, independent of whether a specific trusted brand is
+// being impersonated. For scoring a domain against a known trusted
+// allowlist, see the homoglyph package.
+package homograph
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/jonasboos/phishing-live/homoglyph"
+)
+
+// Severity ranks how suspicious a Finding is.
+type Severity string
+
+const (
+	SeverityLow    Severity = "low"
+	SeverityMedium Severity = "medium"
+	SeverityHigh   Severity = "high"
+)
+
+// Finding is one suspicious trait spotted in a domain label.
+type Finding struct {
+	Label          string   `json:"label"`
+	Script         string   `json:"script,omitempty"`
+	ConfusableWith string   `json:"confusable_with,omitempty"`
+	Severity       Severity `json:"severity"`
+}
+
+// invisibleRunes are zero-width/invisible codepoints phishing kits use to
+// split up a spoofed label so naive substring matching misses it.
+var invisibleRunes = map[rune]bool{
+	'\u200b': true, // zero width space
+	'\u200c': true, // zero width non-joiner
+	'\u200d': true, // zero width joiner
+	'\u2060': true, // word joiner
+	'\ufeff': true, // zero width no-break space / BOM
+}
+
+// scriptOf names r's Unicode script for the scripts this package watches
+// for; anything else (punctuation, digits, unlisted scripts) is reported
+// as "Common" and never triggers a mixed-script Finding.
+func scriptOf(r rune) string {
+	switch {
+	case unicode.Is(unicode.Latin, r):
+		return "Latin"
+	case unicode.Is(unicode.Cyrillic, r):
+		return "Cyrillic"
+	case unicode.Is(unicode.Greek, r):
+		return "Greek"
+	case unicode.Is(unicode.Han, r):
+		return "Han"
+	case unicode.Is(unicode.Hiragana, r):
+		return "Hiragana"
+	case unicode.Is(unicode.Katakana, r):
+		return "Katakana"
+	case unicode.Is(unicode.Hangul, r):
+		return "Hangul"
+	case unicode.Is(unicode.Arabic, r):
+		return "Arabic"
+	default:
+		return "Common"
+	}
+}
+
+// ScanLabel decodes label's Punycode (if any) and reports mixed-script
+// use, confusable Latin lookalikes, and invisible codepoints as a list of
+// Findings. No findings means label looks like an ordinary single-script
+// domain label.
+func ScanLabel(label string) []Finding {
+	unicodeForm := label
+	if homoglyph.HasPunycodeLabel(label) {
+		unicodeForm = homoglyph.ToUnicode(label)
+	}
+
+	var findings []Finding
+	scripts := make(map[string]bool)
+
+	for _, r := range unicodeForm {
+		if invisibleRunes[r] {
+			findings = append(findings, Finding{Label: label, Script: "invisible", Severity: SeverityHigh})
+			continue
+		}
+		if folded, ok := homoglyph.ConfusableFold(r); ok {
+			findings = append(findings, Finding{
+				Label:          label,
+				Script:         scriptOf(r),
+				ConfusableWith: string(folded),
+				Severity:       SeverityHigh,
+			})
+			continue
+		}
+		if s := scriptOf(r); s != "Common" {
+			scripts[s] = true
+		}
+	}
+
+	if len(scripts) > 1 {
+		names := make([]string, 0, len(scripts))
+		for s := range scripts {
+			names = append(names, s)
+		}
+		sort.Strings(names)
+		findings = append(findings, Finding{
+			Label:    label,
+			Script:   strings.Join(names, "+"),
+			Severity: SeverityMedium,
+		})
+	}
+
+	return findings
+}
+
+// ScanDomain splits domain into its dot-separated labels and returns the
+// combined Findings across all of them.
+func ScanDomain(domain string) []Finding {
+	var findings []Finding
+	for _, label := range strings.Split(domain, ".") {
+		findings = append(findings, ScanLabel(label)...)
+	}
+	return findings
+}
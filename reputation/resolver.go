@@ -0,0 +1,235 @@
+package reputation
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// dohEndpoint and dotServer are Cloudflare's public resolver, used as the
+// first two links in the DoH -> DoT -> system-resolver fallback chain
+// resolveHost degrades through, mirroring how AdGuard-style filters keep
+// resolving even when a plaintext DNS lookup would be blocked or tampered
+// with in transit.
+const (
+	dohEndpoint = "https://cloudflare-dns.com/dns-query"
+	dotServer   = "1.1.1.1:853"
+)
+
+// resolveHost looks up name's A records, trying DoH first, then DoT, then
+// falling back to the system resolver - each tier only attempted if the
+// previous one errored (a transport failure or malformed response), not
+// merely because it returned no records: a definitive NXDOMAIN answer from
+// DoH or DoT is the final answer and never re-resolved a second way.
+func resolveHost(ctx context.Context, name string) ([]string, error) {
+	if ips, err := dohLookupA(ctx, name); err == nil {
+		return ips, nil
+	}
+	if ips, err := dotLookupA(ctx, name); err == nil {
+		return ips, nil
+	}
+	var resolver net.Resolver
+	return resolver.LookupHost(ctx, name)
+}
+
+// dohResponse is the minimal shape of the DoH JSON API both Cloudflare and
+// Google serve (RFC 8427-adjacent, not RFC itself, but a de facto
+// standard).
+type dohResponse struct {
+	Status int `json:"Status"` // 0 = NOERROR, 3 = NXDOMAIN
+	Answer []struct {
+		Type int    `json:"type"`
+		Data string `json:"data"`
+	} `json:"Answer"`
+}
+
+// dohLookupA resolves name's A records via Cloudflare's DoH JSON API. A
+// definitive NXDOMAIN answer (Status 3) returns (nil, nil) - no such host,
+// but not an error - so resolveHost treats it as the final clean answer
+// instead of falling back to DoT. Any transport or malformed-response
+// failure returns an error so resolveHost falls back.
+func dohLookupA(ctx context.Context, name string) ([]string, error) {
+	url := fmt.Sprintf("%s?name=%s&type=A", dohEndpoint, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/dns-json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reputation: DoH status %d", resp.StatusCode)
+	}
+
+	var doh dohResponse
+	if err := json.NewDecoder(resp.Body).Decode(&doh); err != nil {
+		return nil, err
+	}
+	if doh.Status == 3 {
+		return nil, nil
+	}
+	if doh.Status != 0 {
+		return nil, fmt.Errorf("reputation: DoH response code %d", doh.Status)
+	}
+
+	var ips []string
+	for _, a := range doh.Answer {
+		if a.Type == 1 {
+			ips = append(ips, a.Data)
+		}
+	}
+	return ips, nil
+}
+
+// dotLookupA resolves name's A records via DNS-over-TLS (RFC 7858) against
+// Cloudflare's resolver: a hand-rolled single-question A query, since the
+// wire format is simple enough not to warrant pulling in a full DNS
+// library for this one fallback tier.
+func dotLookupA(ctx context.Context, name string) ([]string, error) {
+	dialer := &tls.Dialer{Config: &tls.Config{ServerName: "cloudflare-dns.com"}}
+	conn, err := dialer.DialContext(ctx, "tcp", dotServer)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	query, id := buildDNSQuery(name)
+	framed := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(framed, uint16(len(query)))
+	copy(framed[2:], query)
+	if _, err := conn.Write(framed); err != nil {
+		return nil, err
+	}
+
+	var lenBuf [2]byte
+	if _, err := io.ReadFull(conn, lenBuf[:]); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(lenBuf[:]))
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+
+	return parseDNSResponse(resp, id)
+}
+
+// buildDNSQuery encodes a minimal single-question A-record query (RFC 1035
+// §4.1) for name, returning the wire bytes and the random ID the response
+// must echo back.
+func buildDNSQuery(name string) ([]byte, uint16) {
+	id := uint16(rand.Intn(1 << 16))
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, id)
+	binary.Write(&buf, binary.BigEndian, uint16(0x0100)) // standard query, recursion desired
+	binary.Write(&buf, binary.BigEndian, uint16(1))      // QDCOUNT
+	binary.Write(&buf, binary.BigEndian, uint16(0))      // ANCOUNT
+	binary.Write(&buf, binary.BigEndian, uint16(0))      // NSCOUNT
+	binary.Write(&buf, binary.BigEndian, uint16(0))      // ARCOUNT
+
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		buf.WriteByte(byte(len(label)))
+		buf.WriteString(label)
+	}
+	buf.WriteByte(0) // root label
+
+	binary.Write(&buf, binary.BigEndian, uint16(1)) // QTYPE A
+	binary.Write(&buf, binary.BigEndian, uint16(1)) // QCLASS IN
+
+	return buf.Bytes(), id
+}
+
+// parseDNSResponse decodes resp (the message body, without the DoT 2-byte
+// length prefix) into its A records, verifying it answers the query
+// identified by wantID. An NXDOMAIN response code returns (nil, nil); any
+// other non-success response code is an error.
+func parseDNSResponse(resp []byte, wantID uint16) ([]string, error) {
+	if len(resp) < 12 {
+		return nil, fmt.Errorf("reputation: DNS response too short")
+	}
+	if binary.BigEndian.Uint16(resp[0:2]) != wantID {
+		return nil, fmt.Errorf("reputation: DNS response ID mismatch")
+	}
+	flags := binary.BigEndian.Uint16(resp[2:4])
+	rcode := flags & 0x000F
+	qdCount := binary.BigEndian.Uint16(resp[4:6])
+	anCount := binary.BigEndian.Uint16(resp[6:8])
+
+	offset := 12
+	for i := 0; i < int(qdCount); i++ {
+		var err error
+		offset, err = skipDNSName(resp, offset)
+		if err != nil {
+			return nil, err
+		}
+		offset += 4 // QTYPE + QCLASS
+	}
+
+	if rcode == 3 {
+		return nil, nil
+	}
+	if rcode != 0 {
+		return nil, fmt.Errorf("reputation: DNS response code %d", rcode)
+	}
+
+	var ips []string
+	for i := 0; i < int(anCount); i++ {
+		var err error
+		offset, err = skipDNSName(resp, offset)
+		if err != nil {
+			return nil, err
+		}
+		if offset+10 > len(resp) {
+			return nil, fmt.Errorf("reputation: truncated DNS answer record")
+		}
+		rrType := binary.BigEndian.Uint16(resp[offset : offset+2])
+		rdLength := int(binary.BigEndian.Uint16(resp[offset+8 : offset+10]))
+		offset += 10
+		if offset+rdLength > len(resp) {
+			return nil, fmt.Errorf("reputation: truncated DNS answer rdata")
+		}
+		if rrType == 1 && rdLength == 4 {
+			ips = append(ips, net.IP(resp[offset:offset+4]).String())
+		}
+		offset += rdLength
+	}
+	return ips, nil
+}
+
+// skipDNSName advances past a DNS-encoded name starting at offset, which
+// per RFC 1035 §4.1.4 may end in either a zero-length root label or a
+// compression pointer, and returns the offset of the byte following it.
+func skipDNSName(msg []byte, offset int) (int, error) {
+	for {
+		if offset >= len(msg) {
+			return 0, fmt.Errorf("reputation: DNS name runs past end of message")
+		}
+		length := msg[offset]
+		switch {
+		case length == 0:
+			return offset + 1, nil
+		case length&0xC0 == 0xC0:
+			if offset+1 >= len(msg) {
+				return 0, fmt.Errorf("reputation: truncated DNS name pointer")
+			}
+			return offset + 2, nil
+		default:
+			offset += 1 + int(length)
+		}
+	}
+}